@@ -0,0 +1,70 @@
+package fins
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// AccessRightError Error when the access right is held by another node
+type AccessRightError struct {
+	Holder Address
+}
+
+func (e *AccessRightError) Error() string {
+	return fmt.Sprintf("access right is held by network %d node %d unit %d",
+		e.Holder.Network, e.Holder.Node, e.Holder.Unit)
+}
+
+// AcquireAccessRight Acquires the access right of the CPU unit, which prevents other nodes from changing its
+// program and operating mode. An *AccessRightError reports the node holding the access right on failure
+func (c *Client) AcquireAccessRight() error {
+	r, e := c.execute(accessRightCommand(CommandCodeAccessRightAcquire))
+	var ec *EndCodeError
+	if errors.As(e, &ec) && ec.EndCode == EndCodeAccessWriteErrorNoAccessRight && len(r.Data) >= 3 {
+		return &AccessRightError{
+			Holder: Address{
+				Network: r.Data[0],
+				Node:    r.Data[1],
+				Unit:    r.Data[2],
+			},
+		}
+	}
+	return e
+}
+
+// ForceAcquireAccessRight Acquires the access right of the CPU unit even if another node holds it
+func (c *Client) ForceAcquireAccessRight() error {
+	_, e := c.execute(accessRightCommand(CommandCodeAccessRightForcedAcquire))
+	return e
+}
+
+// ReleaseAccessRight Releases the access right of the CPU unit
+func (c *Client) ReleaseAccessRight() error {
+	_, e := c.execute(accessRightCommand(CommandCodeAccessRightRelease))
+	return e
+}
+
+// WithAccessRight Acquires the access right, runs fn and releases the access right again, even if fn fails or
+// panics. fn is not run if the access right cannot be acquired
+func (c *Client) WithAccessRight(fn func() error) (err error) {
+	if e := c.AcquireAccessRight(); e != nil {
+		return e
+	}
+	defer func() {
+		if e := c.ReleaseAccessRight(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	return fn()
+}
+
+func accessRightCommand(commandCode uint16) *Payload {
+	p := &Payload{
+		CommandCode: commandCode,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(p.Data, ProgramNumberCurrent)
+	return p
+}
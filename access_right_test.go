@@ -0,0 +1,68 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessRight(t *testing.T) {
+	s, c := newSimulator(t)
+	other := newSimulatorClient(t, s, 3)
+
+	require.NoError(t, c.AcquireAccessRight())
+	require.NoError(t, c.AcquireAccessRight(), "acquiring the access right again")
+
+	e := other.AcquireAccessRight()
+	var ae *AccessRightError
+	require.True(t, errors.As(e, &ae), "error %v", e)
+	assert.Equal(t, Address{Network: 0, Node: 2, Unit: 0}, ae.Holder)
+	assert.Equal(t, "access right is held by network 0 node 2 unit 0", e.Error())
+	assert.True(t, errors.Is(other.Run(), ErrModeChangeNoAccessRight))
+	require.NoError(t, other.WriteWords(MemoryAreaDMWord, 0, []uint16{1}), "writing memory without the access right")
+
+	// Releasing an access right held by another node has no effect
+	require.NoError(t, other.ReleaseAccessRight())
+	assert.True(t, errors.As(other.AcquireAccessRight(), &ae))
+
+	require.NoError(t, other.ForceAcquireAccessRight())
+	require.NoError(t, other.Run())
+	assert.True(t, errors.As(c.AcquireAccessRight(), &ae))
+	assert.Equal(t, Address{Network: 0, Node: 3, Unit: 0}, ae.Holder)
+
+	require.NoError(t, other.ReleaseAccessRight())
+	require.NoError(t, c.Stop())
+}
+
+func TestWithAccessRight(t *testing.T) {
+	s, c := newSimulator(t)
+	other := newSimulatorClient(t, s, 3)
+
+	failure := errors.New("failure")
+	e := c.WithAccessRight(func() error {
+		var ae *AccessRightError
+		assert.True(t, errors.As(other.AcquireAccessRight(), &ae), "access right held while fn runs")
+		return failure
+	})
+	assert.Equal(t, failure, e)
+	require.NoError(t, other.AcquireAccessRight(), "access right released after fn fails")
+
+	called := false
+	e = c.WithAccessRight(func() error {
+		called = true
+		return nil
+	})
+	var ae *AccessRightError
+	assert.True(t, errors.As(e, &ae), "error %v", e)
+	assert.False(t, called)
+
+	require.NoError(t, other.ReleaseAccessRight())
+	assert.Panics(t, func() {
+		_ = c.WithAccessRight(func() error {
+			panic("failure")
+		})
+	})
+	require.NoError(t, other.AcquireAccessRight(), "access right released after fn panics")
+}
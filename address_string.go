@@ -0,0 +1,146 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAddress Error when an address string cannot be parsed
+var ErrInvalidAddress = errors.New("invalid address")
+
+// addressPrefixes Name in the memory area catalogue of each prefix accepted in address strings
+var addressPrefixes = map[string]string{
+	"":    "CIO",
+	"CIO": "CIO",
+	"W":   "W",
+	"WR":  "W",
+	"H":   "H",
+	"HR":  "H",
+	"A":   "A",
+	"AR":  "A",
+	"D":   "D",
+	"DM":  "D",
+	"E":   "E",
+	"EM":  "E",
+	"T":   "T",
+	"TIM": "T",
+	"C":   "C",
+	"CNT": "C",
+	"IR":  "IR",
+	"DR":  "DR",
+}
+
+// ParseIOAddress Parses an address in the notation of CX-Programmer, such as D100, DM100, W20.05, H5, A100.15,
+// CIO 1.03, 1.03, E1_200 or T10, into an address with the CS and CJ series memory area code. An address with a bit
+// number selects the bit memory area and one without the word memory area; timers and counters select their PV, and
+// their completion flags are at the same address of MemoryAreaTimerCounterCompletionFlag. A number beyond the area
+// of CS and CJ series CPU units is an ErrAddressOutOfRange
+func ParseIOAddress(s string) (IOAddress, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+
+	var name, rest string
+	if n := strings.IndexByte(text, '_'); n > 0 && text[0] == 'E' {
+		bank, e := strconv.ParseUint(strings.TrimPrefix(text[1:n], "M"), 16, 8)
+		if e != nil || bank > uint64(EMBankMax) {
+			return IOAddress{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+		}
+		name = fmt.Sprintf("E%X", bank)
+		rest = text[n+1:]
+	} else {
+		n := 0
+		for n < len(text) && text[n] >= 'A' && text[n] <= 'Z' {
+			n++
+		}
+		prefix, ok := addressPrefixes[text[:n]]
+		if !ok {
+			return IOAddress{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+		}
+		name = prefix
+		rest = strings.TrimSpace(text[n:])
+	}
+
+	word, bit := rest, ""
+	if n := strings.IndexByte(rest, '.'); n >= 0 {
+		word, bit = rest[:n], rest[n+1:]
+	}
+	number, e := strconv.ParseUint(word, 10, 16)
+	if e != nil {
+		return IOAddress{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+	var bitOffset uint64
+	if bit != "" {
+		bitOffset, e = strconv.ParseUint(bit, 10, 8)
+		if e != nil || bitOffset > 15 {
+			return IOAddress{}, fmt.Errorf("%w: bit number of %q", ErrInvalidAddress, s)
+		}
+	}
+
+	for _, area := range memoryAreaCatalogue {
+		if area.Name != name || !addressElementMatches(area.Element, bit != "") {
+			continue
+		}
+		if !area.holds(int(number)) {
+			return IOAddress{}, fmt.Errorf("%w: %q", ErrAddressOutOfRange, s)
+		}
+		ioAddr := IOAddress{
+			MemoryArea: area.Code,
+			Address:    area.Offset + uint16(number),
+			BitOffset:  byte(bitOffset),
+		}
+		return ioAddr, nil
+	}
+	if bit != "" {
+		return IOAddress{}, fmt.Errorf("%w: %s area has no bits: %q", ErrInvalidAddress, name, s)
+	}
+	return IOAddress{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+}
+
+// emBank Returns the extended memory bank of the memory area code of its bits or words
+func emBank(code byte) (byte, bool) {
+	for bank := byte(0); bank <= EMBankMax; bank++ {
+		if code == emBitCode(bank) || code == emWordCode(bank) {
+			return bank, true
+		}
+	}
+	return 0, false
+}
+
+func addressElementMatches(element MemoryAreaElement, bit bool) bool {
+	if bit {
+		return element == ElementBit
+	}
+	return element == ElementWord || element == ElementDoubleWord
+}
+
+// FormatIOAddress Formats an address with a CS and CJ series memory area code in the notation of CX-Programmer, the
+// reverse of ParseIOAddress
+func FormatIOAddress(ioAddr IOAddress) (string, error) {
+	area, ok := LookupMemoryArea(ioAddr.MemoryArea, ioAddr.Address)
+	if !ok || ioAddr.Address < area.Offset {
+		return "", fmt.Errorf("%w: memory area 0x%02x address %d", ErrInvalidAddress, ioAddr.MemoryArea,
+			ioAddr.Address)
+	}
+
+	prefix := area.Name
+	if prefix == "CIO" {
+		prefix = "CIO "
+	} else if _, ok := emBank(area.Code); ok {
+		prefix += "_"
+	}
+	number := ioAddr.Address - area.Offset
+	if area.Element == ElementBit || area.Element == ElementBitForced {
+		return fmt.Sprintf("%s%d.%02d", prefix, number, ioAddr.BitOffset), nil
+	}
+	return fmt.Sprintf("%s%d", prefix, number), nil
+}
+
+// String Returns the address in the notation of CX-Programmer, or its memory area code and address if it has none
+func (a IOAddress) String() string {
+	s, e := FormatIOAddress(a)
+	if e != nil {
+		return fmt.Sprintf("0x%02x:%d.%02d", a.MemoryArea, a.Address, a.BitOffset)
+	}
+	return s
+}
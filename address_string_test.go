@@ -0,0 +1,103 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIOAddress(t *testing.T) {
+	tests := []struct {
+		s      string
+		ioAddr IOAddress
+		format string
+	}{
+		{"D100", IOAddress{MemoryArea: MemoryAreaDMWord, Address: 100}, "D100"},
+		{"dm 32767", IOAddress{MemoryArea: MemoryAreaDMWord, Address: 32767}, "D32767"},
+		{"D10.15", IOAddress{MemoryArea: MemoryAreaDMBit, Address: 10, BitOffset: 15}, "D10.15"},
+		{"W20.05", IOAddress{MemoryArea: MemoryAreaWRBit, Address: 20, BitOffset: 5}, "W20.05"},
+		{"HR5", IOAddress{MemoryArea: MemoryAreaHRWord, Address: 5}, "H5"},
+		{"A100.15", IOAddress{MemoryArea: MemoryAreaARBit, Address: 100, BitOffset: 15}, "A100.15"},
+		{"CIO 1.03", IOAddress{MemoryArea: MemoryAreaCIOBit, Address: 1, BitOffset: 3}, "CIO 1.03"},
+		{"1.03", IOAddress{MemoryArea: MemoryAreaCIOBit, Address: 1, BitOffset: 3}, "CIO 1.03"},
+		{"6143", IOAddress{MemoryArea: MemoryAreaCIOWord, Address: 6143}, "CIO 6143"},
+		{"T10", IOAddress{MemoryArea: MemoryAreaTimerCounterPV, Address: 10}, "T10"},
+		{"CNT10", IOAddress{MemoryArea: MemoryAreaTimerCounterPV, Address: CounterAddressOffset + 10}, "C10"},
+		{"E200", IOAddress{MemoryArea: MemoryAreaEMCurrentWord, Address: 200}, "E200"},
+		{"E1_200", IOAddress{MemoryArea: 0xa1, Address: 200}, "E1_200"},
+		{"EM1_200.07", IOAddress{MemoryArea: 0x21, Address: 200, BitOffset: 7}, "E1_200.07"},
+		{"EB_100", IOAddress{MemoryArea: 0xab, Address: 100}, "EB_100"},
+		{"EB_100.01", IOAddress{MemoryArea: 0x2b, Address: 100, BitOffset: 1}, "EB_100.01"},
+		{"E18_32767", IOAddress{MemoryArea: 0x68, Address: 32767}, "E18_32767"},
+		{"IR15", IOAddress{MemoryArea: MemoryAreaIndexRegisterPV, Address: 15}, "IR15"},
+		{"DR0", IOAddress{MemoryArea: MemoryAreaDataRegisterPV, Address: 0}, "DR0"},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			ioAddr, e := ParseIOAddress(test.s)
+			require.NoError(t, e)
+			assert.Equal(t, test.ioAddr, ioAddr)
+			s, e := FormatIOAddress(ioAddr)
+			require.NoError(t, e)
+			assert.Equal(t, test.format, s)
+		})
+	}
+}
+
+func TestParseIOAddressInvalid(t *testing.T) {
+	tests := []struct {
+		s   string
+		err error
+	}{
+		{"", ErrInvalidAddress},
+		{"X10", ErrInvalidAddress},
+		{"D", ErrInvalidAddress},
+		{"D-1", ErrInvalidAddress},
+		{"D10.16", ErrInvalidAddress},
+		{"T10.01", ErrInvalidAddress},
+		{"E19_0", ErrInvalidAddress},
+		{"EB100", ErrInvalidAddress},
+		{"D32768", ErrAddressOutOfRange},
+		{"D40000", ErrAddressOutOfRange},
+		{"W600", ErrAddressOutOfRange},
+		{"W512.00", ErrAddressOutOfRange},
+		{"C4096", ErrAddressOutOfRange},
+		{"E1_32768", ErrAddressOutOfRange},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			_, e := ParseIOAddress(test.s)
+			assert.True(t, errors.Is(e, test.err), "error %v", e)
+		})
+	}
+}
+
+func TestFormatIOAddressRoundTrip(t *testing.T) {
+	// The first and last element of every area with an address notation in CX-Programmer; the bank number register
+	// and the condition flags have none, and forced status and completion flags share the address of their bits and
+	// words
+	for _, area := range memoryAreaCatalogue {
+		if area.Code == MemoryAreaEMCurrentBankNumber || area.Code == MemoryAreaClockPulsesConditionFlagsBit {
+			continue
+		}
+		if area.Element != ElementBit && area.Element != ElementWord && area.Element != ElementDoubleWord {
+			continue
+		}
+		for _, n := range []int{area.First, area.First + area.Count - 1} {
+			ioAddr := IOAddress{MemoryArea: area.Code, Address: area.Offset + uint16(n)}
+			if area.Element == ElementBit {
+				ioAddr.BitOffset = 15
+			}
+			s, e := FormatIOAddress(ioAddr)
+			require.NoError(t, e)
+			parsed, e := ParseIOAddress(s)
+			require.NoError(t, e, s)
+			assert.Equal(t, ioAddr, parsed, s)
+		}
+	}
+
+	_, e := FormatIOAddress(IOAddress{MemoryArea: 0xff})
+	assert.True(t, errors.Is(e, ErrInvalidAddress))
+}
@@ -0,0 +1,305 @@
+package fins
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// BCDFormat Format of signed BCD values, as selected by the BCDS, BINS, BCDSL and BINSL instructions
+type BCDFormat byte
+
+const (
+	// BCDFormat0 Leftmost digit F for negative values: -999 to 9999, or -9999999 to 99999999 on two words
+	BCDFormat0 BCDFormat = iota
+
+	// BCDFormat1 Leftmost bit set for negative values: -7999 to 7999, or -79999999 to 79999999 on two words
+	BCDFormat1
+
+	// BCDFormat2 Leftmost digit A for negative and 0 for positive values: -999 to 999, or -9999999 to 9999999 on two
+	// words
+	BCDFormat2
+
+	// BCDFormat3 Leftmost digit F for negative values and E for negative values with a leftmost digit of 1: -1999 to
+	// 9999, or -19999999 to 99999999 on two words
+	BCDFormat3
+)
+
+// ErrInvalidBCDFormat Error when a signed BCD format is unknown
+var ErrInvalidBCDFormat = errors.New("invalid signed BCD format")
+
+// decodeBCDValue Decodes a BCD value of the given number of words
+func decodeBCDValue(raw uint64, size int) (uint64, error) {
+	bcd := make([]byte, 8)
+	binary.BigEndian.PutUint64(bcd, raw)
+	x, e := decodePackedBCD(bcd[8-2*size:])
+	if e != nil {
+		return 0, fmt.Errorf("%w: 0x%0*x", e, 4*size, raw)
+	}
+	return x, nil
+}
+
+// encodeBCDValue Encodes a BCD value of the given number of words
+func encodeBCDValue(x uint64, size int) (uint64, error) {
+	bcd, e := encodePackedBCD(x, 2*size)
+	if e != nil {
+		return 0, fmt.Errorf("%w: %d in %d words", e, x, size)
+	}
+	var raw uint64
+	for _, b := range bcd {
+		raw = raw<<8 | uint64(b)
+	}
+	return raw, nil
+}
+
+// decodeSignedBCD Decodes a signed BCD value of the given number of words
+func decodeSignedBCD(raw uint64, size int, format BCDFormat) (int64, error) {
+	shift := uint(16*size - 4)
+	lead := raw >> shift & 0x0f
+	rest := raw &^ (0x0f << shift)
+
+	var x uint64
+	var e error
+	negative := false
+	switch format {
+	case BCDFormat0:
+		negative = lead == 0x0f
+		if negative {
+			raw = rest
+		}
+		x, e = decodeBCDValue(raw, size)
+	case BCDFormat1:
+		negative = lead&0x08 != 0
+		x, e = decodeBCDValue(raw&^(0x08<<shift), size)
+	case BCDFormat2:
+		if lead != 0x00 && lead != 0x0a {
+			return 0, fmt.Errorf("%w: 0x%0*x", ErrBCDBadDigit, 4*size, raw)
+		}
+		negative = lead == 0x0a
+		x, e = decodeBCDValue(rest, size)
+	case BCDFormat3:
+		switch lead {
+		case 0x0f:
+			negative = true
+			x, e = decodeBCDValue(rest, size)
+		case 0x0e:
+			negative = true
+			x, e = decodeBCDValue(rest|0x01<<shift, size)
+		default:
+			x, e = decodeBCDValue(raw, size)
+		}
+	default:
+		return 0, ErrInvalidBCDFormat
+	}
+	if e != nil {
+		return 0, e
+	}
+	if negative {
+		return -int64(x), nil
+	}
+	return int64(x), nil
+}
+
+// encodeSignedBCD Encodes a signed BCD value of the given number of words
+func encodeSignedBCD(x int64, size int, format BCDFormat) (uint64, error) {
+	shift := uint(16*size - 4)
+	lead := uint64(1)
+	for i := 1; i < 4*size; i++ {
+		lead *= 10
+	}
+	negative := x < 0
+	m := uint64(x)
+	if negative {
+		m = uint64(-x)
+	}
+
+	var limit, sign uint64
+	switch format {
+	case BCDFormat0:
+		limit, sign = 10*lead, 0x0f
+		if negative {
+			limit = lead
+		}
+	case BCDFormat1:
+		limit, sign = 8*lead, 0x08
+	case BCDFormat2:
+		limit, sign = lead, 0x0a
+	case BCDFormat3:
+		limit, sign = 10*lead, 0x0f
+		if negative {
+			limit = lead
+			if m >= lead && m < 2*lead {
+				m -= lead
+				sign = 0x0e
+			}
+		}
+	default:
+		return 0, ErrInvalidBCDFormat
+	}
+	if m >= limit {
+		return 0, fmt.Errorf("%w: %d in %d words of format %d", ErrBCDOverflow, x, size, format)
+	}
+
+	raw, e := encodeBCDValue(m, size)
+	if e != nil {
+		return 0, e
+	}
+	if negative {
+		raw |= sign << shift
+	}
+	return raw, nil
+}
+
+func (c *Client) readBCD(memoryArea byte, address uint16, readCount uint16, size int) ([]uint64, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, size)
+	if e != nil {
+		return nil, e
+	}
+	for i, v := range raw {
+		raw[i], e = decodeBCDValue(v, size)
+		if e != nil {
+			return nil, e
+		}
+	}
+	return raw, nil
+}
+
+func (c *Client) writeBCD(memoryArea byte, address uint16, data []uint64, size int) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		var e error
+		raw[i], e = encodeBCDValue(v, size)
+		if e != nil {
+			return e
+		}
+	}
+	return c.writeValues(memoryArea, address, raw, size)
+}
+
+func (c *Client) readSignedBCD(memoryArea byte, address uint16, readCount uint16, size int,
+	format BCDFormat) ([]int64, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, size)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]int64, len(raw))
+	for i, v := range raw {
+		data[i], e = decodeSignedBCD(v, size, format)
+		if e != nil {
+			return nil, e
+		}
+	}
+	return data, nil
+}
+
+func (c *Client) writeSignedBCD(memoryArea byte, address uint16, data []int64, size int, format BCDFormat) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		var e error
+		raw[i], e = encodeSignedBCD(v, size, format)
+		if e != nil {
+			return e
+		}
+	}
+	return c.writeValues(memoryArea, address, raw, size)
+}
+
+// ReadBCD16s Reads BCD values of one word, 0 to 9999, from the PLC data area
+func (c *Client) ReadBCD16s(memoryArea byte, address uint16, readCount uint16) ([]uint16, error) {
+	raw, e := c.readBCD(memoryArea, address, readCount, 1)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]uint16, len(raw))
+	for i, v := range raw {
+		data[i] = uint16(v)
+	}
+	return data, nil
+}
+
+// ReadBCD32s Reads BCD values of two words, 0 to 99999999, from the PLC data area
+func (c *Client) ReadBCD32s(memoryArea byte, address uint16, readCount uint16) ([]uint32, error) {
+	raw, e := c.readBCD(memoryArea, address, readCount, 2)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]uint32, len(raw))
+	for i, v := range raw {
+		data[i] = uint32(v)
+	}
+	return data, nil
+}
+
+// ReadBCD64s Reads BCD values of four words, 0 to 9999999999999999, from the PLC data area
+func (c *Client) ReadBCD64s(memoryArea byte, address uint16, readCount uint16) ([]uint64, error) {
+	return c.readBCD(memoryArea, address, readCount, 4)
+}
+
+// ReadSignedBCD16s Reads signed BCD values of one word in the given format from the PLC data area
+func (c *Client) ReadSignedBCD16s(memoryArea byte, address uint16, readCount uint16,
+	format BCDFormat) ([]int16, error) {
+	raw, e := c.readSignedBCD(memoryArea, address, readCount, 1, format)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]int16, len(raw))
+	for i, v := range raw {
+		data[i] = int16(v)
+	}
+	return data, nil
+}
+
+// ReadSignedBCD32s Reads signed BCD values of two words in the given format from the PLC data area
+func (c *Client) ReadSignedBCD32s(memoryArea byte, address uint16, readCount uint16,
+	format BCDFormat) ([]int32, error) {
+	raw, e := c.readSignedBCD(memoryArea, address, readCount, 2, format)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]int32, len(raw))
+	for i, v := range raw {
+		data[i] = int32(v)
+	}
+	return data, nil
+}
+
+// WriteBCD16s Writes BCD values of one word to the PLC data area
+func (c *Client) WriteBCD16s(memoryArea byte, address uint16, data []uint16) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = uint64(v)
+	}
+	return c.writeBCD(memoryArea, address, raw, 1)
+}
+
+// WriteBCD32s Writes BCD values of two words to the PLC data area
+func (c *Client) WriteBCD32s(memoryArea byte, address uint16, data []uint32) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = uint64(v)
+	}
+	return c.writeBCD(memoryArea, address, raw, 2)
+}
+
+// WriteBCD64s Writes BCD values of four words to the PLC data area
+func (c *Client) WriteBCD64s(memoryArea byte, address uint16, data []uint64) error {
+	return c.writeBCD(memoryArea, address, data, 4)
+}
+
+// WriteSignedBCD16s Writes signed BCD values of one word in the given format to the PLC data area
+func (c *Client) WriteSignedBCD16s(memoryArea byte, address uint16, data []int16, format BCDFormat) error {
+	raw := make([]int64, len(data))
+	for i, v := range data {
+		raw[i] = int64(v)
+	}
+	return c.writeSignedBCD(memoryArea, address, raw, 1, format)
+}
+
+// WriteSignedBCD32s Writes signed BCD values of two words in the given format to the PLC data area
+func (c *Client) WriteSignedBCD32s(memoryArea byte, address uint16, data []int32, format BCDFormat) error {
+	raw := make([]int64, len(data))
+	for i, v := range data {
+		raw[i] = int64(v)
+	}
+	return c.writeSignedBCD(memoryArea, address, raw, 2, format)
+}
@@ -0,0 +1,145 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedBCDRanges Smallest and largest value of each signed BCD format on one and two words
+var signedBCDRanges = map[BCDFormat][2][2]int64{
+	BCDFormat0: {{-999, 9999}, {-9999999, 99999999}},
+	BCDFormat1: {{-7999, 7999}, {-79999999, 79999999}},
+	BCDFormat2: {{-999, 999}, {-9999999, 9999999}},
+	BCDFormat3: {{-1999, 9999}, {-19999999, 99999999}},
+}
+
+func TestEncodeSignedBCD(t *testing.T) {
+	tests := []struct {
+		x      int64
+		size   int
+		format BCDFormat
+		raw    uint64
+	}{
+		{1234, 1, BCDFormat0, 0x1234},
+		{-999, 1, BCDFormat0, 0xf999},
+		{-1, 2, BCDFormat0, 0xf0000001},
+		{7999, 1, BCDFormat1, 0x7999},
+		{-7999, 1, BCDFormat1, 0xf999},
+		{-1, 2, BCDFormat1, 0x80000001},
+		{999, 1, BCDFormat2, 0x0999},
+		{-999, 1, BCDFormat2, 0xa999},
+		{-9999999, 2, BCDFormat2, 0xa9999999},
+		{9999, 1, BCDFormat3, 0x9999},
+		{-999, 1, BCDFormat3, 0xf999},
+		{-1000, 1, BCDFormat3, 0xe000},
+		{-1999, 1, BCDFormat3, 0xe999},
+		{-19999999, 2, BCDFormat3, 0xe9999999},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d in %d words of format %d", test.x, test.size, test.format), func(t *testing.T) {
+			raw, e := encodeSignedBCD(test.x, test.size, test.format)
+			require.NoError(t, e)
+			assert.Equal(t, test.raw, raw)
+			x, e := decodeSignedBCD(test.raw, test.size, test.format)
+			require.NoError(t, e)
+			assert.Equal(t, test.x, x)
+		})
+	}
+}
+
+func TestSignedBCDRoundTrip(t *testing.T) {
+	for format, ranges := range signedBCDRanges {
+		// Every value of one word, and the values around the limits and the leading digits of two words
+		values := [][]int64{nil, nil}
+		for x := int64(-10000); x <= 10000; x++ {
+			values[0] = append(values[0], x)
+		}
+		for _, x := range []int64{0, 1, -1, 9999999, -9999999, 10000000, -10000000, 19999999, -19999999, 20000000,
+			-20000000, -25000000, -29999999, 79999999, -79999999, 80000000, -80000000, 99999999, -99999999,
+			100000000, -100000000} {
+			values[1] = append(values[1], x)
+		}
+
+		for size := 1; size <= 2; size++ {
+			min, max := ranges[size-1][0], ranges[size-1][1]
+			t.Run(fmt.Sprintf("format %d in %d words", format, size), func(t *testing.T) {
+				for _, x := range values[size-1] {
+					raw, e := encodeSignedBCD(x, size, format)
+					if x < min || x > max {
+						assert.True(t, errors.Is(e, ErrBCDOverflow), "%d: error %v", x, e)
+						continue
+					}
+					require.NoError(t, e, x)
+					decoded, e := decodeSignedBCD(raw, size, format)
+					require.NoError(t, e, "%d: 0x%x", x, raw)
+					assert.Equal(t, x, decoded, "0x%x", raw)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeSignedBCDInvalid(t *testing.T) {
+	tests := []struct {
+		raw    uint64
+		format BCDFormat
+		err    error
+	}{
+		{0x1999, BCDFormat2, ErrBCDBadDigit},
+		{0xb999, BCDFormat2, ErrBCDBadDigit},
+		{0x0a00, BCDFormat0, ErrBCDBadDigit},
+		{0xfa00, BCDFormat0, ErrBCDBadDigit},
+		{0x0999, BCDFormat(4), ErrInvalidBCDFormat},
+	}
+	for _, test := range tests {
+		_, e := decodeSignedBCD(test.raw, 1, test.format)
+		assert.True(t, errors.Is(e, test.err), "0x%04x in format %d: error %v", test.raw, test.format, e)
+	}
+	_, e := encodeSignedBCD(0, 1, BCDFormat(4))
+	assert.True(t, errors.Is(e, ErrInvalidBCDFormat))
+}
+
+func TestReadWriteBCD(t *testing.T) {
+	_, c := newSimulator(t)
+
+	require.NoError(t, c.WriteBCD16s(MemoryAreaDMWord, 0, []uint16{0, 1234, 9999}))
+	words, e := c.ReadWords(MemoryAreaDMWord, 0, 3)
+	require.NoError(t, e)
+	assert.Equal(t, []uint16{0x0000, 0x1234, 0x9999}, words)
+	bcd16, e := c.ReadBCD16s(MemoryAreaDMWord, 0, 3)
+	require.NoError(t, e)
+	assert.Equal(t, []uint16{0, 1234, 9999}, bcd16)
+	assert.True(t, errors.Is(c.WriteBCD16s(MemoryAreaDMWord, 0, []uint16{10000}), ErrBCDOverflow))
+
+	require.NoError(t, c.WriteBCD32s(MemoryAreaDMWord, 10, []uint32{12345678}))
+	bcd32, e := c.ReadBCD32s(MemoryAreaDMWord, 10, 1)
+	require.NoError(t, e)
+	assert.Equal(t, []uint32{12345678}, bcd32)
+
+	require.NoError(t, c.WriteBCD64s(MemoryAreaDMWord, 20, []uint64{1234567890123456}))
+	bcd64, e := c.ReadBCD64s(MemoryAreaDMWord, 20, 1)
+	require.NoError(t, e)
+	assert.Equal(t, []uint64{1234567890123456}, bcd64)
+
+	require.NoError(t, c.WriteWords(MemoryAreaDMWord, 30, []uint16{0x12a4}))
+	_, e = c.ReadBCD16s(MemoryAreaDMWord, 30, 1)
+	assert.True(t, errors.Is(e, ErrBCDBadDigit), "error %v", e)
+
+	require.NoError(t, c.WriteSignedBCD16s(MemoryAreaDMWord, 40, []int16{-1999, 9999}, BCDFormat3))
+	words, e = c.ReadWords(MemoryAreaDMWord, 40, 2)
+	require.NoError(t, e)
+	assert.Equal(t, []uint16{0xe999, 0x9999}, words)
+	signed16, e := c.ReadSignedBCD16s(MemoryAreaDMWord, 40, 2, BCDFormat3)
+	require.NoError(t, e)
+	assert.Equal(t, []int16{-1999, 9999}, signed16)
+	assert.True(t, errors.Is(c.WriteSignedBCD16s(MemoryAreaDMWord, 40, []int16{-2999}, BCDFormat3), ErrBCDOverflow))
+
+	require.NoError(t, c.WriteSignedBCD32s(MemoryAreaDMWord, 50, []int32{-79999999}, BCDFormat1))
+	signed32, e := c.ReadSignedBCD32s(MemoryAreaDMWord, 50, 1, BCDFormat1)
+	require.NoError(t, e)
+	assert.Equal(t, []int32{-79999999}, signed32)
+}
@@ -0,0 +1,159 @@
+package fins
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SetParallelFrames Sets the number of frames of a read or write split by the frame limit of the profile that are
+// sent at once, 1 by default to send them one after the other
+func (c *Client) SetParallelFrames(n int) {
+	if n < 1 {
+		n = 1
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.parallelFrames = n
+}
+
+// ParallelFrames Returns the number of frames of a split read or write sent at once
+func (c *Client) ParallelFrames() int {
+	c.Lock()
+	defer c.Unlock()
+	if c.parallelFrames < 1 {
+		return 1
+	}
+	return c.parallelFrames
+}
+
+// memoryAreaChunk The part of a read or write sent in one frame, starting at element first of the transfer
+type memoryAreaChunk struct {
+	ioAddr IOAddress
+	first  int
+	count  int
+}
+
+// splitMemoryArea Splits a transfer of count elements into chunks of at most max elements, or one chunk when max is 0.
+// A transfer running past the last address of the memory area code is an ErrAddressOutOfRange
+func splitMemoryArea(ioAddr IOAddress, count int, max int) ([]memoryAreaChunk, error) {
+	element, _ := memoryAreaElement(ioAddr.MemoryArea)
+	bits := element == ElementBit || element == ElementBitForced
+	last := int(ioAddr.Address) + count - 1
+	if bits {
+		last = (int(ioAddr.Address)*16 + int(ioAddr.BitOffset) + count - 1) / 16
+	}
+	if last > 0xffff {
+		return nil, fmt.Errorf("%w: %d elements from %v", ErrAddressOutOfRange, count, ioAddr)
+	}
+
+	if max < 1 || count <= max {
+		return []memoryAreaChunk{{ioAddr: ioAddr, count: count}}, nil
+	}
+	chunks := make([]memoryAreaChunk, 0, (count+max-1)/max)
+	for first := 0; first < count; first += max {
+		chunk := memoryAreaChunk{
+			ioAddr: ioAddr,
+			first:  first,
+			count:  max,
+		}
+		if count-first < max {
+			chunk.count = count - first
+		}
+		if bits {
+			bit := int(ioAddr.BitOffset) + first
+			chunk.ioAddr.Address = ioAddr.Address + uint16(bit/16)
+			chunk.ioAddr.BitOffset = byte(bit % 16)
+		} else {
+			chunk.ioAddr.Address = ioAddr.Address + uint16(first)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// sendParallel Calls fn for n frames numbered from 0, sending up to ParallelFrames at once, and returns the first
+// error. No frame is sent after an error, though frames already sent complete
+func (c *Client) sendParallel(n int, fn func(i int) error) error {
+	parallel := c.ParallelFrames()
+	if parallel == 1 || n == 1 {
+		for i := 0; i < n; i++ {
+			if e := fn(i); e != nil {
+				return e
+			}
+		}
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var first error
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return first != nil
+	}
+	slots := make(chan struct{}, parallel)
+	for i := 0; i < n; i++ {
+		slots <- struct{}{}
+		if failed() {
+			<-slots
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if e := fn(i); e != nil {
+				mutex.Lock()
+				if first == nil {
+					first = e
+				}
+				mutex.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return first
+}
+
+// readMemoryArea Reads count elements of size bytes each, in as many frames as the profile requires
+func (c *Client) readMemoryArea(ioAddr IOAddress, count int, size int) ([]byte, error) {
+	max := c.Profile().frameElements(CommandCodeMemoryAreaRead, ioAddr.MemoryArea)
+	chunks, e := splitMemoryArea(ioAddr, count, max)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]byte, count*size)
+	e = c.sendParallel(len(chunks), func(i int) error {
+		chunk := chunks[i]
+		r, e := c.execute(readCommand(chunk.ioAddr, uint16(chunk.count)))
+		if e != nil {
+			return e
+		}
+		if len(r.Data) < chunk.count*size {
+			return ErrResponseTooShort
+		}
+		copy(data[chunk.first*size:], r.Data[:chunk.count*size])
+		return nil
+	})
+	if e != nil {
+		return nil, e
+	}
+	return data, nil
+}
+
+// writeMemoryArea Writes elements of size bytes each, in as many frames as the profile requires. A write split in
+// several frames is not atomic: frames written before an error keep their data, and the frames after it are not sent
+func (c *Client) writeMemoryArea(ioAddr IOAddress, data []byte, size int) error {
+	max := c.Profile().frameElements(CommandCodeMemoryAreaWrite, ioAddr.MemoryArea)
+	chunks, e := splitMemoryArea(ioAddr, len(data)/size, max)
+	if e != nil {
+		return e
+	}
+	return c.sendParallel(len(chunks), func(i int) error {
+		chunk := chunks[i]
+		bytes := data[chunk.first*size : (chunk.first+chunk.count)*size]
+		_, e := c.execute(writeCommand(chunk.ioAddr, uint16(chunk.count), bytes))
+		return e
+	})
+}
@@ -0,0 +1,189 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMemoryArea(t *testing.T) {
+	tests := []struct {
+		name   string
+		ioAddr IOAddress
+		count  int
+		max    int
+		want   []memoryAreaChunk
+	}{
+		{
+			name:   "words",
+			ioAddr: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 100},
+			count:  2500,
+			max:    999,
+			want: []memoryAreaChunk{
+				{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 100}, 0, 999},
+				{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 1099}, 999, 999},
+				{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 2098}, 1998, 502},
+			},
+		},
+		{
+			name:   "bits carry into the next word",
+			ioAddr: IOAddress{MemoryArea: MemoryAreaDMBit, Address: 10, BitOffset: 5},
+			count:  2500,
+			max:    999,
+			want: []memoryAreaChunk{
+				{IOAddress{MemoryArea: MemoryAreaDMBit, Address: 10, BitOffset: 5}, 0, 999},
+				{IOAddress{MemoryArea: MemoryAreaDMBit, Address: 72, BitOffset: 12}, 999, 999},
+				{IOAddress{MemoryArea: MemoryAreaDMBit, Address: 135, BitOffset: 3}, 1998, 502},
+			},
+		},
+		{
+			name:   "exact multiple",
+			ioAddr: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 0},
+			count:  1998,
+			max:    999,
+			want: []memoryAreaChunk{
+				{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 0}, 0, 999},
+				{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 999}, 999, 999},
+			},
+		},
+		{
+			name:   "within the limit",
+			ioAddr: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 7},
+			count:  10,
+			max:    999,
+			want:   []memoryAreaChunk{{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 7}, 0, 10}},
+		},
+		{
+			name:   "no limit",
+			ioAddr: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 7},
+			count:  5000,
+			max:    0,
+			want:   []memoryAreaChunk{{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 7}, 0, 5000}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunks, e := splitMemoryArea(test.ioAddr, test.count, test.max)
+			require.NoError(t, e)
+			assert.Equal(t, test.want, chunks)
+		})
+	}
+}
+
+func TestSplitMemoryAreaOverflow(t *testing.T) {
+	tests := []struct {
+		ioAddr IOAddress
+		count  int
+		ok     bool
+	}{
+		{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 65000}, 536, true},
+		{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 65000}, 537, false},
+		{IOAddress{MemoryArea: MemoryAreaDMWord, Address: 65535}, 2, false},
+		{IOAddress{MemoryArea: MemoryAreaDMBit, Address: 65535, BitOffset: 15}, 1, true},
+		{IOAddress{MemoryArea: MemoryAreaDMBit, Address: 65535, BitOffset: 15}, 2, false},
+		{IOAddress{MemoryArea: MemoryAreaDMBit, Address: 65000, BitOffset: 0}, 536 * 16, true},
+		{IOAddress{MemoryArea: MemoryAreaDMBit, Address: 65000, BitOffset: 1}, 536 * 16, false},
+	}
+	for _, test := range tests {
+		_, e := splitMemoryArea(test.ioAddr, test.count, 999)
+		if test.ok {
+			assert.NoError(t, e, "%d elements from %v", test.count, test.ioAddr)
+		} else {
+			assert.True(t, errors.Is(e, ErrAddressOutOfRange), "%d elements from %v: error %v", test.count,
+				test.ioAddr, e)
+		}
+	}
+}
+
+func TestSendParallelStopsAfterError(t *testing.T) {
+	for _, parallel := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d parallel frames", parallel), func(t *testing.T) {
+			c := &Client{}
+			c.SetParallelFrames(parallel)
+
+			// The first frame fails at once while the others are still being sent, so no frame is sent after the
+			// ones already started
+			var sent int32
+			failure := errors.New("failure")
+			e := c.sendParallel(100, func(i int) error {
+				atomic.AddInt32(&sent, 1)
+				if i == 0 {
+					return failure
+				}
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+			assert.Equal(t, failure, e)
+			assert.Equal(t, int32(parallel), atomic.LoadInt32(&sent))
+		})
+	}
+}
+
+func TestReadWriteInFrames(t *testing.T) {
+	for _, parallel := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d parallel frames", parallel), func(t *testing.T) {
+			_, c := newSimulator(t)
+			c.SetParallelFrames(parallel)
+
+			words := make([]uint16, 3*ProfileCSCJ.MaxReadWords+17)
+			for i := range words {
+				words[i] = uint16(i*7 + parallel)
+			}
+			require.NoError(t, c.WriteWords(MemoryAreaDMWord, 100, words))
+			read, e := c.ReadWords(MemoryAreaDMWord, 100, uint16(len(words)))
+			require.NoError(t, e)
+			assert.Equal(t, words, read)
+
+			bits := make([]bool, 5000)
+			for i := range bits {
+				bits[i] = i%3 == parallel%3
+			}
+			require.NoError(t, c.WriteBits(MemoryAreaDMBit, 10, 5, bits))
+			readBits, e := c.ReadBits(MemoryAreaDMBit, 10, 5, uint16(len(bits)))
+			require.NoError(t, e)
+			assert.Equal(t, bits, readBits)
+
+			// The bits share their words with the data area
+			read, e = c.ReadWords(MemoryAreaDMWord, 10, 1)
+			require.NoError(t, e)
+			assert.Equal(t, uint16(0), read[0]&0x1f, "bits before the first bit written")
+		})
+	}
+}
+
+func TestReadInFramesError(t *testing.T) {
+	// A profile with a larger data area than the simulator lets the frames past its end fail on the server
+	profile := &Profile{
+		Name: "test",
+		Areas: profileAreas(func(a *MemoryAreaInfo) bool {
+			if a.Name == "D" {
+				a.Count = 40000
+			}
+			return true
+		}),
+		MaxReadWords:  999,
+		MaxWriteWords: 996,
+	}
+
+	for _, parallel := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d parallel frames", parallel), func(t *testing.T) {
+			_, c := newSimulator(t)
+			c.SetProfile(profile)
+			c.SetParallelFrames(parallel)
+
+			read, e := c.ReadWords(MemoryAreaDMWord, 31000, 2500)
+			var ec *EndCodeError
+			require.True(t, errors.As(e, &ec), "error %v", e)
+			assert.Equal(t, EndCodeAddressRangeExceeded, ec.EndCode)
+			assert.Nil(t, read)
+
+			e = c.WriteWords(MemoryAreaDMWord, 31000, make([]uint16, 2500))
+			require.True(t, errors.As(e, &ec), "error %v", e)
+		})
+	}
+}
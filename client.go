@@ -1,7 +1,6 @@
 package fins
 
 import (
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -11,10 +10,18 @@ import (
 
 // Client Omron FINS client
 type Client struct {
-	provider ClientProvider
-	dst      Address
-	src      Address
-	sid      byte
+	provider        ClientProvider
+	dst             Address
+	src             Address
+	sid             byte
+	location        *time.Location
+	profile         *Profile
+	tags            *TagDatabase
+	wordOrder       WordOrder
+	stringOptions   StringOptions
+	parallelFrames  int
+	readPlanOptions ReadPlanOptions
+	readPlans       map[string]*ReadPlan
 
 	sync.Mutex
 }
@@ -25,174 +32,176 @@ func NewClient(provider ClientProvider, dst Address, src Address) *Client {
 	c.provider = provider
 	c.dst = dst
 	c.src = src
+	c.location = time.Local
+	c.profile = ProfileCSCJ
+	c.readPlanOptions = defaultReadPlanOptions
 
 	return c
 }
 
+// SetLocation Sets the time zone of the PLC clock, time.Local by default
+func (c *Client) SetLocation(location *time.Location) {
+	c.Lock()
+	defer c.Unlock()
+	c.location = location
+}
+
+// Location Returns the time zone of the PLC clock
+func (c *Client) Location() *time.Location {
+	c.Lock()
+	defer c.Unlock()
+	return c.location
+}
+
+// SetProfile Sets the profile of the PLC family of the destination, ProfileCSCJ by default
+func (c *Client) SetProfile(profile *Profile) {
+	c.Lock()
+	defer c.Unlock()
+	c.profile = profile
+}
+
+// Profile Returns the profile of the PLC family of the destination
+func (c *Client) Profile() *Profile {
+	c.Lock()
+	defer c.Unlock()
+	return c.profile
+}
+
 // CloseConnection Closes an Omron FINS connection
 func (c *Client) Close() {
 	c.provider.close()
 }
 
-// ReadWords Reads words from the PLC data area
+// ReadWords Reads words from the PLC data area, in several frames when they exceed the frame limit of the profile
 func (c *Client) ReadWords(memoryArea byte, address uint16, readCount uint16) ([]uint16, error) {
 	if !checkIsWordMemoryArea(memoryArea) {
 		return nil, ErrIncompatibleMemoryArea
 	}
-	header := c.nextHeader()
-	command := readCommand(IOAddress{
+	bytes, e := c.readMemoryArea(IOAddress{
 		MemoryArea: memoryArea,
 		Address:    address,
 		BitOffset:  0x00,
-	}, readCount)
-	r, e := c.provider.sendCommand(header, command)
+	}, int(readCount), 2)
 	if e != nil {
 		return nil, e
 	}
 
 	data := make([]uint16, readCount)
 	for i := 0; i < int(readCount); i++ {
-		data[i] = binary.BigEndian.Uint16(r.Data[i*2 : i*2+2])
+		data[i] = binary.BigEndian.Uint16(bytes[i*2 : i*2+2])
 	}
 
 	return data, nil
 }
 
-// ReadString Reads a string from the PLC data area
-func (c *Client) ReadString(memoryArea byte, address uint16, readCount uint16) (*string, error) {
-	if !checkIsWordMemoryArea(memoryArea) {
-		return nil, ErrIncompatibleMemoryArea
-	}
-	header := c.nextHeader()
-	command := readCommand(IOAddress{
-		MemoryArea: memoryArea,
-		Address:    address,
-		BitOffset:  0x00,
-	}, readCount)
-	r, e := c.provider.sendCommand(header, command)
+// ReadString Reads a string of readCount words from the PLC data area, laid out and encoded as set by
+// SetStringOptions
+func (c *Client) ReadString(memoryArea byte, address uint16, readCount uint16) (string, error) {
+	words, e := c.ReadWords(memoryArea, address, readCount)
 	if e != nil {
-		return nil, e
+		return "", e
 	}
-
-	n := bytes.Index(r.Data, []byte{0})
-	s := string(r.Data[:n])
-	return &s, nil
+	return c.StringOptions().decode(words)
 }
 
-// ReadBits Reads bits from the PLC data area
+// ReadBits Reads bits from the PLC data area, in several frames when they exceed the frame limit of the profile
 func (c *Client) ReadBits(memoryArea byte, address uint16, bitOffset byte, readCount uint16) ([]bool, error) {
 	if !checkIsBitMemoryArea(memoryArea) {
 		return nil, ErrIncompatibleMemoryArea
 	}
-	header := c.nextHeader()
-	command := readCommand(IOAddress{
+	bytes, e := c.readMemoryArea(IOAddress{
 		MemoryArea: memoryArea,
 		Address:    address,
 		BitOffset:  bitOffset,
-	}, readCount)
-	r, e := c.provider.sendCommand(header, command)
+	}, int(readCount), 1)
 	if e != nil {
 		return nil, e
 	}
 
 	data := make([]bool, readCount)
 	for i := 0; i < int(readCount); i++ {
-		data[i] = r.Data[i]&0x01 > 0
+		data[i] = bytes[i]&0x01 > 0
 	}
 
 	return data, nil
 }
 
+// Clock Date, time and day of week of the PLC clock
+type Clock struct {
+	Time time.Time
+
+	// Weekday Day of week held by the PLC, which is set independently of the date
+	Weekday time.Weekday
+}
+
 // ReadClock Reads the PLC clock
 func (c *Client) ReadClock() (*time.Time, error) {
-	header := c.nextHeader()
+	clock, e := c.ReadClockData()
+	if e != nil {
+		return nil, e
+	}
+	return &clock.Time, nil
+}
+
+// ReadClockData Reads the date, time and day of week from the PLC clock
+func (c *Client) ReadClockData() (*Clock, error) {
 	command := new(Payload)
 	command.CommandCode = CommandCodeClockRead
 	command.Data = []byte{}
-	r, e := c.provider.sendCommand(header, command)
+	r, e := c.execute(command)
 	if e != nil {
 		return nil, e
 	}
-	year, _ := decodeBCD(r.Data[0:1])
-	if year < 50 {
-		year += 2000
-	} else {
-		year += 1900
-	}
-	month, _ := decodeBCD(r.Data[1:2])
-	day, _ := decodeBCD(r.Data[2:3])
-	hour, _ := decodeBCD(r.Data[3:4])
-	minute, _ := decodeBCD(r.Data[4:5])
-	second, _ := decodeBCD(r.Data[5:6])
 
-	t := time.Date(
-		int(year), time.Month(month), int(day), int(hour), int(minute), int(second),
-		0, // nanosecond
-		time.Local,
-	)
+	return decodeClock(r.Data, c.Location())
+}
 
-	return &t, nil
+// WriteClock Sets the date, time and day of week of the PLC clock
+func (c *Client) WriteClock(t time.Time) error {
+	data, e := encodeClock(t.In(c.Location()))
+	if e != nil {
+		return e
+	}
+	command := new(Payload)
+	command.CommandCode = CommandCodeClockWrite
+	command.Data = data
+	_, e = c.execute(command)
+	return e
 }
 
-// WriteWords Writes words to the PLC data area
+// WriteWords Writes words to the PLC data area, in several frames when they exceed the frame limit of the profile
 func (c *Client) WriteWords(memoryArea byte, address uint16, data []uint16) error {
 	if !checkIsWordMemoryArea(memoryArea) {
 		return ErrIncompatibleMemoryArea
 	}
-	header := c.nextHeader()
 	l := uint16(len(data))
 	bytes := make([]byte, 2*l)
 	for i := 0; i < int(l); i++ {
 		binary.BigEndian.PutUint16(bytes[i*2:i*2+2], data[i])
 	}
-	command := writeCommand(IOAddress{
+
+	return c.writeMemoryArea(IOAddress{
 		MemoryArea: memoryArea,
 		Address:    address,
 		BitOffset:  0x00,
-	}, l, bytes)
-
-	r, e := c.provider.sendCommand(header, command)
-	if e != nil {
-		return e
-	}
-	if r.EndCode != EndCodeNormalCompletion {
-		return fmt.Errorf("error reported by destination, end code 0x%x", r.EndCode)
-	}
-
-	return nil
+	}, bytes, 2)
 }
 
-// WriteString Writes a string to the PLC data area
+// WriteString Writes a string of itemCount words to the PLC data area, laid out and encoded as set by
+// SetStringOptions
 func (c *Client) WriteString(memoryArea byte, address uint16, itemCount uint16, s string) error {
-	if !checkIsWordMemoryArea(memoryArea) {
-		return ErrIncompatibleMemoryArea
-	}
-	header := c.nextHeader()
-	bytes := make([]byte, 2*itemCount)
-	copy(bytes, s)
-	command := writeCommand(IOAddress{
-		MemoryArea: memoryArea,
-		Address:    address,
-		BitOffset:  0x00,
-	}, itemCount, bytes)
-
-	r, e := c.provider.sendCommand(header, command)
+	words, e := c.StringOptions().encode(s, int(itemCount))
 	if e != nil {
 		return e
 	}
-	if r.EndCode != EndCodeNormalCompletion {
-		return fmt.Errorf("error reported by destination, end code 0x%x", r.EndCode)
-	}
-
-	return nil
+	return c.WriteWords(memoryArea, address, words)
 }
 
-// WriteBits Writes bits to the PLC data area
+// WriteBits Writes bits to the PLC data area, in several frames when they exceed the frame limit of the profile
 func (c *Client) WriteBits(memoryArea byte, address uint16, bitOffset byte, data []bool) error {
 	if !checkIsBitMemoryArea(memoryArea) {
 		return ErrIncompatibleMemoryArea
 	}
-	header := c.nextHeader()
 	l := uint16(len(data))
 	bytes := make([]byte, 0, l)
 	var d byte
@@ -204,21 +213,12 @@ func (c *Client) WriteBits(memoryArea byte, address uint16, bitOffset byte, data
 		}
 		bytes = append(bytes, d)
 	}
-	command := writeCommand(IOAddress{
+
+	return c.writeMemoryArea(IOAddress{
 		MemoryArea: memoryArea,
 		Address:    address,
 		BitOffset:  bitOffset,
-	}, l, bytes)
-
-	r, e := c.provider.sendCommand(header, command)
-	if e != nil {
-		return e
-	}
-	if r.EndCode != EndCodeNormalCompletion {
-		return fmt.Errorf("error reported by destination, end code 0x%x", r.EndCode)
-	}
-
-	return nil
+	}, bytes, 1)
 }
 
 // SetBit Sets a bit in the PLC data area
@@ -250,31 +250,50 @@ func (c *Client) bitTwiddle(memoryArea byte, address uint16, bitOffset byte, val
 	if !checkIsBitMemoryArea(memoryArea) {
 		return ErrIncompatibleMemoryArea
 	}
-	header := c.nextHeader()
 	command := writeCommand(IOAddress{
 		MemoryArea: memoryArea,
 		Address:    address,
 		BitOffset:  bitOffset,
 	}, 1, []byte{value})
 
-	r, e := c.provider.sendCommand(header, command)
-	if e != nil {
-		return e
-	}
-	if r.EndCode != EndCodeNormalCompletion {
-		return fmt.Errorf("error reported by destination, end code 0x%x", r.EndCode)
-	}
-
-	return nil
+	_, e := c.execute(command)
+	return e
 }
 
 // ErrIncompatibleMemoryArea Error when the memory area is incompatible with the data type to be read
 var ErrIncompatibleMemoryArea = errors.New("the memory area is incompatible with the data type to be read")
 
-func (c *Client) nextHeader() *Header {
-	sid := c.incrementSid()
-	header := defaultHeader(c.dst, c.src, sid)
-	return header
+// ErrInvalidClockData Error when the PLC clock data is not a valid date and time
+var ErrInvalidClockData = errors.New("the clock data is invalid")
+
+// ErrClockOutOfRange Error when a time is outside the years 1950 to 2049 kept by the PLC clock
+var ErrClockOutOfRange = errors.New("the time is outside the range of the PLC clock")
+
+// ErrResponseTooShort Error when the response data is shorter than the command requires
+var ErrResponseTooShort = errors.New("the response is too short")
+
+// execute Sends a command to the destination and checks the end code of its response
+func (c *Client) execute(command *Payload) (*Response, error) {
+	return c.executeAt(c.dst, command)
+}
+
+// executeAt Sends a command to the given destination, such as another unit of the destination node, and checks the
+// end code of its response
+func (c *Client) executeAt(dst Address, command *Payload) (*Response, error) {
+	command, e := c.Profile().prepare(command)
+	if e != nil {
+		return nil, e
+	}
+	header := defaultHeader(dst, c.src, c.incrementSid())
+	r, e := c.provider.sendCommand(header, command)
+	if e != nil {
+		return nil, e
+	}
+	if code := r.EndCode &^ endCodeFlagsMask; code != EndCodeNormalCompletion {
+		return r, &EndCodeError{EndCode: code}
+	}
+
+	return r, nil
 }
 
 func (c *Client) incrementSid() byte {
@@ -285,22 +304,81 @@ func (c *Client) incrementSid() byte {
 	return sid
 }
 
-func checkIsWordMemoryArea(memoryArea byte) bool {
-	if memoryArea == MemoryAreaDMWord ||
-		memoryArea == MemoryAreaARWord ||
-		memoryArea == MemoryAreaHRWord {
-		return true
+func decodeClock(data []byte, location *time.Location) (*Clock, error) {
+	if len(data) < 6 {
+		return nil, ErrResponseTooShort
+	}
+	if len(data) > 7 {
+		data = data[:7]
+	}
+	fields := make([]int, len(data))
+	for i := range data {
+		v, e := decodePackedBCD(data[i : i+1])
+		if e != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidClockData, e)
+		}
+		fields[i] = int(v)
+	}
+
+	year := fields[0]
+	if year < 50 {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	month, day, hour, minute, second := fields[1], fields[2], fields[3], fields[4], fields[5]
+	if month < 1 || month > 12 || day < 1 || hour > 23 || minute > 59 || second > 59 {
+		return nil, ErrInvalidClockData
 	}
-	return false
+
+	t := time.Date(
+		year, time.Month(month), day, hour, minute, second,
+		0, // nanosecond
+		location,
+	)
+	if t.Day() != day {
+		return nil, ErrInvalidClockData
+	}
+
+	clock := &Clock{
+		Time:    t,
+		Weekday: t.Weekday(),
+	}
+	if len(fields) == 7 {
+		if fields[6] > 6 {
+			return nil, ErrInvalidClockData
+		}
+		clock.Weekday = time.Weekday(fields[6])
+	}
+	return clock, nil
 }
 
-func checkIsBitMemoryArea(memoryArea byte) bool {
-	if memoryArea == MemoryAreaDMBit ||
-		memoryArea == MemoryAreaARBit ||
-		memoryArea == MemoryAreaHRBit {
-		return true
+func encodeClock(t time.Time) ([]byte, error) {
+	if t.Year() < 1950 || t.Year() > 2049 {
+		return nil, ErrClockOutOfRange
+	}
+	fields := []int{t.Year() % 100, int(t.Month()), t.Day(), t.Hour(), t.Minute(), t.Second(), int(t.Weekday())}
+	data := make([]byte, 0, len(fields))
+	for _, f := range fields {
+		bcd, e := encodePackedBCD(uint64(f), 1)
+		if e != nil {
+			return nil, e
+		}
+		data = append(data, bcd...)
 	}
-	return false
+	return data, nil
+}
+
+// checkIsWordMemoryArea Reports whether the memory area holds words, read and written two bytes per element
+func checkIsWordMemoryArea(memoryArea byte) bool {
+	element, ok := memoryAreaElement(memoryArea)
+	return ok && element == ElementWord
+}
+
+// checkIsBitMemoryArea Reports whether the memory area holds bits or flags, read and written one byte per element
+func checkIsBitMemoryArea(memoryArea byte) bool {
+	element, ok := memoryAreaElement(memoryArea)
+	return ok && (element == ElementBit || element == ElementFlag)
 }
 
 // @ToDo Asynchronous functions
@@ -0,0 +1,66 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockData(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	leap := time.Date(2024, time.February, 29, 13, 45, 30, 0, tokyo)
+	data, e := encodeClock(leap)
+	require.NoError(t, e)
+	assert.Equal(t, []byte{0x24, 0x02, 0x29, 0x13, 0x45, 0x30, 0x04}, data)
+
+	clock, e := decodeClock(data, tokyo)
+	require.NoError(t, e)
+	assert.Equal(t, &Clock{Time: leap, Weekday: time.Thursday}, clock)
+
+	// The day of week is set independently of the date, and derived from it when the PLC omits it
+	clock, e = decodeClock([]byte{0x24, 0x02, 0x29, 0x13, 0x45, 0x30, 0x00}, tokyo)
+	require.NoError(t, e)
+	assert.Equal(t, time.Sunday, clock.Weekday)
+	clock, e = decodeClock([]byte{0x50, 0x01, 0x01, 0x00, 0x00, 0x00}, time.UTC)
+	require.NoError(t, e)
+	assert.Equal(t, &Clock{Time: time.Date(1950, time.January, 1, 0, 0, 0, 0, time.UTC), Weekday: time.Sunday}, clock)
+
+	for _, invalid := range [][]byte{
+		{0x23, 0x02, 0x29, 0x00, 0x00, 0x00},       // no leap day
+		{0x24, 0x13, 0x01, 0x00, 0x00, 0x00},       // month
+		{0x24, 0x01, 0x01, 0x24, 0x00, 0x00},       // hour
+		{0x24, 0x01, 0x01, 0x00, 0x00, 0x00, 0x07}, // day of week
+		{0x24, 0x01, 0x0a, 0x00, 0x00, 0x00},       // BCD digit
+	} {
+		_, e = decodeClock(invalid, time.UTC)
+		assert.True(t, errors.Is(e, ErrInvalidClockData), "% x: error %v", invalid, e)
+	}
+	_, e = decodeClock([]byte{0x24, 0x01, 0x01, 0x00, 0x00}, time.UTC)
+	assert.Equal(t, ErrResponseTooShort, e)
+
+	_, e = encodeClock(time.Date(2050, time.January, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, ErrClockOutOfRange, e)
+	_, e = encodeClock(time.Date(1949, time.December, 31, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, ErrClockOutOfRange, e)
+}
+
+func TestReadWriteClock(t *testing.T) {
+	_, c := newSimulator(t)
+	assert.Equal(t, time.Local, c.Location())
+
+	set := time.Date(2030, time.June, 15, 12, 0, 0, 0, time.Local)
+	require.NoError(t, c.WriteClock(set))
+	clock, e := c.ReadClockData()
+	require.NoError(t, e)
+	assert.WithinDuration(t, set, clock.Time, 2*time.Second)
+	assert.Equal(t, time.Saturday, clock.Weekday)
+
+	read, e := c.ReadClock()
+	require.NoError(t, e)
+	assert.WithinDuration(t, set, *read, 2*time.Second)
+
+	assert.Equal(t, ErrClockOutOfRange, c.WriteClock(time.Date(2050, time.January, 1, 0, 0, 0, 0, time.Local)))
+}
@@ -0,0 +1,212 @@
+package fins
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ClockDrift A measurement of the offset of a PLC clock from the host clock
+type ClockDrift struct {
+	// Time Host time of the measurement
+	Time time.Time
+
+	// Drift PLC clock minus host clock
+	Drift time.Duration
+
+	// RoundTrip Round trip time of the clock read used for the measurement
+	RoundTrip time.Duration
+
+	// Corrected True if the PLC clock was set to the host clock after the measurement
+	Corrected bool
+}
+
+const (
+	// clockPollInterval Interval between clock reads while waiting for the PLC clock to tick over
+	clockPollInterval = 10 * time.Millisecond
+
+	// clockPollTimeout Longest time to wait for the PLC clock to tick over
+	clockPollTimeout = 2500 * time.Millisecond
+)
+
+// ErrClockNotRunning Error when the PLC clock does not advance while its drift is measured
+var ErrClockNotRunning = errors.New("the PLC clock is not running")
+
+// MeasureClockDrift Measures the offset of the PLC clock from the host clock. As the PLC clock only has a resolution
+// of one second, the clock is polled until it ticks over so the drift is known to within about half the round trip
+// time rather than to the second
+func (c *Client) MeasureClockDrift() (*ClockDrift, error) {
+	var previous *Clock
+	var previousMidpoint time.Time
+	start := time.Now()
+
+	for time.Since(start) < clockPollTimeout {
+		sent := time.Now()
+		clock, e := c.ReadClockData()
+		if e != nil {
+			return nil, e
+		}
+		received := time.Now()
+		roundTrip := received.Sub(sent)
+		midpoint := sent.Add(roundTrip / 2)
+
+		if previous != nil && clock.Time.After(previous.Time) {
+			// the PLC clock ticked over between the previous read and this one
+			tick := previousMidpoint.Add(midpoint.Sub(previousMidpoint) / 2)
+			d := &ClockDrift{
+				Time:      received,
+				Drift:     clock.Time.Sub(tick),
+				RoundTrip: roundTrip,
+			}
+			return d, nil
+		}
+
+		previous, previousMidpoint = clock, midpoint
+		time.Sleep(clockPollInterval)
+	}
+
+	return nil, ErrClockNotRunning
+}
+
+// SynchronizeClock Sets the PLC clock to the host clock. The PLC clock only holds whole seconds, so the write is
+// delayed until it arrives at the PLC on a second boundary, estimating the transit time as half the round trip
+func (c *Client) SynchronizeClock(roundTrip time.Duration) error {
+	transit := roundTrip / 2
+	next := time.Now().Add(transit).Truncate(time.Second).Add(time.Second)
+	time.Sleep(time.Until(next.Add(-transit)))
+	return c.WriteClock(next)
+}
+
+// ClockSynchronizer Keeps the clocks of a set of PLCs aligned to the host clock, correcting a PLC clock whenever its
+// drift exceeds a threshold, and keeps the drift history of each PLC
+type ClockSynchronizer struct {
+	threshold   time.Duration
+	historySize int
+	clients     map[string]*Client
+	history     map[string][]ClockDrift
+
+	sync.Mutex
+}
+
+// NewClockSynchronizer Creates a synchronizer correcting clocks that drift by more than threshold and keeping up to
+// historySize measurements per PLC, at least 1
+func NewClockSynchronizer(threshold time.Duration, historySize int) *ClockSynchronizer {
+	if historySize < 1 {
+		historySize = 1
+	}
+	s := new(ClockSynchronizer)
+	s.threshold = threshold
+	s.historySize = historySize
+	s.clients = make(map[string]*Client)
+	s.history = make(map[string][]ClockDrift)
+	return s
+}
+
+// Add Adds a PLC to be kept synchronized under the given name
+func (s *ClockSynchronizer) Add(name string, c *Client) {
+	s.Lock()
+	defer s.Unlock()
+	s.clients[name] = c
+}
+
+// Remove Stops keeping the named PLC synchronized and discards its history
+func (s *ClockSynchronizer) Remove(name string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.clients, name)
+	delete(s.history, name)
+}
+
+// Names Returns the names of the PLCs kept synchronized, sorted
+func (s *ClockSynchronizer) Names() []string {
+	s.Lock()
+	defer s.Unlock()
+	names := make([]string, 0, len(s.clients))
+	for name := range s.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// History Returns the drift measurements of the named PLC, oldest first
+func (s *ClockSynchronizer) History(name string) []ClockDrift {
+	s.Lock()
+	defer s.Unlock()
+	return append([]ClockDrift(nil), s.history[name]...)
+}
+
+// Synchronize Measures the drift of the named PLC clock and corrects it if it exceeds the threshold
+func (s *ClockSynchronizer) Synchronize(name string) (*ClockDrift, error) {
+	s.Lock()
+	c, ok := s.clients[name]
+	s.Unlock()
+	if !ok {
+		return nil, errors.New("unknown PLC " + name)
+	}
+
+	d, e := c.MeasureClockDrift()
+	if e != nil {
+		return nil, e
+	}
+	if d.Drift > s.threshold || d.Drift < -s.threshold {
+		if e := c.SynchronizeClock(d.RoundTrip); e != nil {
+			return nil, e
+		}
+		d.Corrected = true
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.clients[name]; ok {
+		h := append(s.history[name], *d)
+		if len(h) > s.historySize {
+			h = h[len(h)-s.historySize:]
+		}
+		s.history[name] = h
+	}
+	return d, nil
+}
+
+// SynchronizeAll Synchronizes every PLC concurrently, returning the errors by PLC name
+func (s *ClockSynchronizer) SynchronizeAll() map[string]error {
+	names := s.Names()
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if _, e := s.Synchronize(name); e != nil {
+				mu.Lock()
+				errs[name] = e
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+	return errs
+}
+
+// Run Synchronizes every PLC every interval until the context is done, calling onError for each PLC that fails
+func (s *ClockSynchronizer) Run(ctx context.Context, interval time.Duration, onError func(name string, e error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for name, e := range s.SynchronizeAll() {
+			if onError != nil {
+				onError(name, e)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
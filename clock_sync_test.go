@@ -0,0 +1,51 @@
+package fins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureClockDrift(t *testing.T) {
+	_, c := newSimulator(t)
+
+	// The PLC clock only holds whole seconds, so it is set up to one second short of an hour ahead
+	require.NoError(t, c.WriteClock(time.Now().Add(time.Hour)))
+	d, e := c.MeasureClockDrift()
+	require.NoError(t, e)
+	assert.InDelta(t, float64(time.Hour-time.Second/2), float64(d.Drift), float64(600*time.Millisecond))
+	assert.Less(t, int64(d.RoundTrip), int64(time.Second))
+	assert.False(t, d.Corrected)
+}
+
+func TestClockSynchronizer(t *testing.T) {
+	_, fast := newSimulator(t)
+	_, slow := newSimulator(t)
+	require.NoError(t, fast.WriteClock(time.Now().Add(time.Minute)))
+	require.NoError(t, slow.WriteClock(time.Now().Add(-time.Minute)))
+
+	s := NewClockSynchronizer(10*time.Second, 1)
+	s.Add("slow", slow)
+	s.Add("fast", fast)
+	s.Add("removed", fast)
+	s.Remove("removed")
+	assert.Equal(t, []string{"fast", "slow"}, s.Names())
+	_, e := s.Synchronize("unknown")
+	assert.Error(t, e)
+
+	assert.Empty(t, s.SynchronizeAll())
+	for _, name := range s.Names() {
+		history := s.History(name)
+		require.Len(t, history, 1, name)
+		assert.True(t, history[0].Corrected, name)
+	}
+
+	// Once corrected, the clock is within the threshold and only the last measurement is kept
+	d, e := s.Synchronize("fast")
+	require.NoError(t, e)
+	assert.False(t, d.Corrected)
+	assert.InDelta(t, 0, float64(d.Drift), float64(time.Second))
+	assert.Equal(t, []ClockDrift{*d}, s.History("fast"))
+}
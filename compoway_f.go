@@ -0,0 +1,386 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+const (
+	compoWayFSTX byte = 0x02
+	compoWayFETX byte = 0x03
+
+	// compoWayFHeaderLength Length of the node number, sub-address and SID of a command
+	compoWayFHeaderLength = 5
+
+	// compoWayFResponseHeaderLength Length of the node number, sub-address, end code, MRC, SRC, MRES and SRES of a
+	// response
+	compoWayFResponseHeaderLength = 14
+
+	compoWayFNodeMax = 99
+)
+
+const (
+	// CompoWayFReadVariables Main and sub request code: read variable area
+	CompoWayFReadVariables uint16 = 0x0101
+
+	// CompoWayFWriteVariables Main and sub request code: write variable area
+	CompoWayFWriteVariables uint16 = 0x0102
+
+	// CompoWayFReadAttributes Main and sub request code: read controller attributes
+	CompoWayFReadAttributes uint16 = 0x0503
+
+	// CompoWayFReadStatus Main and sub request code: read controller status
+	CompoWayFReadStatus uint16 = 0x0601
+
+	// CompoWayFEchoback Main and sub request code: echoback test
+	CompoWayFEchoback uint16 = 0x0801
+
+	// CompoWayFOperationCommand Main and sub request code: operation command
+	CompoWayFOperationCommand uint16 = 0x3005
+)
+
+// ErrInvalidCompoWayFFrame Error when a CompoWay/F frame is malformed
+var ErrInvalidCompoWayFFrame = errors.New("invalid CompoWay/F frame")
+
+// ErrCompoWayFBCC Error when the block check character of a CompoWay/F frame is wrong
+var ErrCompoWayFBCC = errors.New("CompoWay/F block check character mismatch")
+
+// CompoWayFCommand A CompoWay/F command
+type CompoWayFCommand struct {
+	// Node Node number of the device, 0 to 99
+	Node byte
+
+	// RequestCode Main request code MRC in the upper and sub request code SRC in the lower byte
+	RequestCode uint16
+
+	// Data Command text following the request code, in ASCII
+	Data []byte
+}
+
+// CompoWayFResponse A CompoWay/F response
+type CompoWayFResponse struct {
+	Node byte
+
+	// EndCode End code reporting errors in the frame received by the device, 0 if the frame was accepted
+	EndCode byte
+
+	// RequestCode Main and sub request code of the command
+	RequestCode uint16
+
+	// ResponseCode Result of the command, 0 on normal completion
+	ResponseCode uint16
+
+	// Data Response text following the response code, in ASCII
+	Data []byte
+}
+
+// CompoWayFEndCodeError Error when a CompoWay/F device rejects the frame of a command
+type CompoWayFEndCodeError struct {
+	EndCode byte
+}
+
+var compoWayFEndCodes = map[byte]string{
+	0x0f: "FINS command error",
+	0x10: "parity error",
+	0x11: "framing error",
+	0x12: "overrun error",
+	0x13: "BCC error",
+	0x14: "format error",
+	0x16: "sub-address error",
+	0x18: "frame length error",
+}
+
+func (e *CompoWayFEndCodeError) Error() string {
+	if text, ok := compoWayFEndCodes[e.EndCode]; ok {
+		return fmt.Sprintf("CompoWay/F %s, end code %02X", text, e.EndCode)
+	}
+	return fmt.Sprintf("CompoWay/F end code %02X", e.EndCode)
+}
+
+// CompoWayFResponseError Error when a CompoWay/F device cannot execute a command
+type CompoWayFResponseError struct {
+	ResponseCode uint16
+}
+
+var compoWayFResponseCodes = map[uint16]string{
+	0x1001: "command too long",
+	0x1002: "command too short",
+	0x1003: "number of elements and data do not agree",
+	0x1100: "parameter error",
+	0x1101: "area type error",
+	0x1103: "start address out of range",
+	0x1104: "end address out of range",
+	0x110b: "response too long",
+	0x2203: "operation error",
+	0x3003: "read-only data",
+	0x0401: "unsupported command",
+}
+
+func (e *CompoWayFResponseError) Error() string {
+	if text, ok := compoWayFResponseCodes[e.ResponseCode]; ok {
+		return fmt.Sprintf("CompoWay/F %s, response code %04X", text, e.ResponseCode)
+	}
+	return fmt.Sprintf("CompoWay/F response code %04X", e.ResponseCode)
+}
+
+// EncodeCompoWayFCommand Encodes a command as a CompoWay/F serial frame, with STX, ETX and block check character
+func EncodeCompoWayFCommand(command *CompoWayFCommand) ([]byte, error) {
+	text, e := encodeCompoWayFText(command)
+	if e != nil {
+		return nil, e
+	}
+	return compoWayFFrame(text), nil
+}
+
+// DecodeCompoWayFResponse Decodes a CompoWay/F serial frame holding a response, checking its block check character
+func DecodeCompoWayFResponse(frame []byte) (*CompoWayFResponse, error) {
+	text, e := compoWayFText(frame)
+	if e != nil {
+		return nil, e
+	}
+	return decodeCompoWayFResponseText(text)
+}
+
+// DecodeCompoWayFCommand Decodes a CompoWay/F serial frame holding a command, checking its block check character
+func DecodeCompoWayFCommand(frame []byte) (*CompoWayFCommand, error) {
+	text, e := compoWayFText(frame)
+	if e != nil {
+		return nil, e
+	}
+	return decodeCompoWayFText(text)
+}
+
+// EncodeCompoWayFResponse Encodes a response as a CompoWay/F serial frame, with STX, ETX and block check character
+func EncodeCompoWayFResponse(response *CompoWayFResponse) ([]byte, error) {
+	text, e := encodeCompoWayFResponseText(response)
+	if e != nil {
+		return nil, e
+	}
+	return compoWayFFrame(text), nil
+}
+
+// compoWayFFrame Wraps the text of a command or response in STX, ETX and the block check character, which is the
+// exclusive or of every byte after STX up to and including ETX
+func compoWayFFrame(text []byte) []byte {
+	frame := make([]byte, 0, len(text)+3)
+	frame = append(frame, compoWayFSTX)
+	frame = append(frame, text...)
+	frame = append(frame, compoWayFETX)
+	return append(frame, compoWayFBCC(frame[1:]))
+}
+
+func compoWayFText(frame []byte) ([]byte, error) {
+	if len(frame) < 3 || frame[0] != compoWayFSTX || frame[len(frame)-2] != compoWayFETX {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	if compoWayFBCC(frame[1:len(frame)-1]) != frame[len(frame)-1] {
+		return nil, ErrCompoWayFBCC
+	}
+	return frame[1 : len(frame)-2], nil
+}
+
+func compoWayFBCC(data []byte) byte {
+	var bcc byte
+	for _, b := range data {
+		bcc ^= b
+	}
+	return bcc
+}
+
+// encodeCompoWayFText Encodes the node number, sub-address, SID, request code and data of a command, the part of
+// the frame carried by the FINS serial gateway
+func encodeCompoWayFText(command *CompoWayFCommand) ([]byte, error) {
+	if command.Node > compoWayFNodeMax {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	text := make([]byte, 0, compoWayFHeaderLength+4+len(command.Data))
+	text = append(text, fmt.Sprintf("%02d000%04X", command.Node, command.RequestCode)...)
+	return append(text, command.Data...), nil
+}
+
+func decodeCompoWayFText(text []byte) (*CompoWayFCommand, error) {
+	if len(text) < compoWayFHeaderLength+4 {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	node, e := strconv.ParseUint(string(text[0:2]), 10, 8)
+	if e != nil {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	requestCode, e := parseCompoWayFHex(text[5:9])
+	if e != nil {
+		return nil, e
+	}
+	command := &CompoWayFCommand{
+		Node:        byte(node),
+		RequestCode: uint16(requestCode),
+		Data:        text[9:],
+	}
+	return command, nil
+}
+
+func encodeCompoWayFResponseText(response *CompoWayFResponse) ([]byte, error) {
+	if response.Node > compoWayFNodeMax {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	text := make([]byte, 0, compoWayFResponseHeaderLength+len(response.Data))
+	text = append(text, fmt.Sprintf("%02d00%02X", response.Node, response.EndCode)...)
+	if response.EndCode != 0 {
+		return text, nil
+	}
+	text = append(text, fmt.Sprintf("%04X%04X", response.RequestCode, response.ResponseCode)...)
+	return append(text, response.Data...), nil
+}
+
+func decodeCompoWayFResponseText(text []byte) (*CompoWayFResponse, error) {
+	if len(text) < 6 {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	node, e := strconv.ParseUint(string(text[0:2]), 10, 8)
+	if e != nil {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	endCode, e := parseCompoWayFHex(text[4:6])
+	if e != nil {
+		return nil, e
+	}
+	response := &CompoWayFResponse{
+		Node:    byte(node),
+		EndCode: byte(endCode),
+	}
+	if endCode != 0 {
+		return response, nil
+	}
+
+	if len(text) < compoWayFResponseHeaderLength {
+		return nil, ErrInvalidCompoWayFFrame
+	}
+	requestCode, e := parseCompoWayFHex(text[6:10])
+	if e != nil {
+		return nil, e
+	}
+	responseCode, e := parseCompoWayFHex(text[10:14])
+	if e != nil {
+		return nil, e
+	}
+	response.RequestCode = uint16(requestCode)
+	response.ResponseCode = uint16(responseCode)
+	response.Data = text[compoWayFResponseHeaderLength:]
+	return response, nil
+}
+
+func parseCompoWayFHex(text []byte) (uint64, error) {
+	n, e := strconv.ParseUint(string(text), 16, 64)
+	if e != nil {
+		return 0, ErrInvalidCompoWayFFrame
+	}
+	return n, nil
+}
+
+// CompoWayF A CompoWay/F device, such as a temperature controller or digital panel meter, connected to a serial
+// port of the PLC and reached through the FINS serial gateway
+type CompoWayF struct {
+	client *Client
+	port   Address
+	node   byte
+}
+
+// CompoWayF Returns the CompoWay/F device with the given node number on the serial port with the given unit
+// address, see SerialUnitPort
+func (c *Client) CompoWayF(unit byte, node byte) *CompoWayF {
+	d := new(CompoWayF)
+	d.client = c
+	d.port = c.serialPort(unit)
+	d.node = node
+	return d
+}
+
+// Execute Sends a command to the device and returns its response data. A *CompoWayFEndCodeError or
+// *CompoWayFResponseError reports commands the device rejects
+func (d *CompoWayF) Execute(requestCode uint16, data []byte) ([]byte, error) {
+	text, e := encodeCompoWayFText(&CompoWayFCommand{
+		Node:        d.node,
+		RequestCode: requestCode,
+		Data:        data,
+	})
+	if e != nil {
+		return nil, e
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeConvertToCompoWayFCommand,
+		Data:        text,
+	}
+	r, e := d.client.executeAt(d.port, command)
+	if e != nil {
+		return nil, e
+	}
+	response, e := decodeCompoWayFResponseText(r.Data)
+	if e != nil {
+		return nil, e
+	}
+	if response.EndCode != 0 {
+		return nil, &CompoWayFEndCodeError{EndCode: response.EndCode}
+	}
+	if response.ResponseCode != 0 {
+		return nil, &CompoWayFResponseError{ResponseCode: response.ResponseCode}
+	}
+	return response.Data, nil
+}
+
+// ReadVariables Reads count elements of a variable area starting at address. Elements of variable types 0x80 to 0x8f
+// hold four hexadecimal digits and are sign extended from 16 bits, those of other variable types hold eight
+func (d *CompoWayF) ReadVariables(variableType byte, address uint16, count uint16) ([]int32, error) {
+	data, e := d.Execute(CompoWayFReadVariables, []byte(fmt.Sprintf("%02X%04X00%04X", variableType, address, count)))
+	if e != nil {
+		return nil, e
+	}
+
+	digits := compoWayFVariableDigits(variableType)
+	if len(data) < int(count)*digits {
+		return nil, ErrResponseTooShort
+	}
+	values := make([]int32, count)
+	for i := range values {
+		n, e := parseCompoWayFHex(data[i*digits : (i+1)*digits])
+		if e != nil {
+			return nil, e
+		}
+		if digits == 4 {
+			values[i] = int32(int16(n))
+		} else {
+			values[i] = int32(uint32(n))
+		}
+	}
+	return values, nil
+}
+
+// WriteVariables Writes elements of a variable area starting at address. Most devices only accept writes after
+// writing through communications is enabled with an operation command
+func (d *CompoWayF) WriteVariables(variableType byte, address uint16, values []int32) error {
+	digits := compoWayFVariableDigits(variableType)
+	data := []byte(fmt.Sprintf("%02X%04X00%04X", variableType, address, len(values)))
+	for _, v := range values {
+		if digits == 4 {
+			data = append(data, fmt.Sprintf("%04X", uint16(v))...)
+		} else {
+			data = append(data, fmt.Sprintf("%08X", uint32(v))...)
+		}
+	}
+	_, e := d.Execute(CompoWayFWriteVariables, data)
+	return e
+}
+
+// OperationCommand Sends an operation command with its related information, such as enabling writing through
+// communications or switching between run and stop
+func (d *CompoWayF) OperationCommand(code byte, info byte) error {
+	_, e := d.Execute(CompoWayFOperationCommand, []byte(fmt.Sprintf("%02X%02X", code, info)))
+	return e
+}
+
+func compoWayFVariableDigits(variableType byte) int {
+	if variableType&0xf0 == 0x80 {
+		return 4
+	}
+	return 8
+}
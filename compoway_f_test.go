@@ -0,0 +1,136 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompoWayFCommandFrame(t *testing.T) {
+	// Read one element of variable type C0 at address 0003 of node 01, with the block check character computed over
+	// 010000101C00003000001 and ETX
+	command := &CompoWayFCommand{Node: 1, RequestCode: CompoWayFReadVariables, Data: []byte("C00003000001")}
+	frame := []byte("\x02010000101C00003000001\x03\x43")
+
+	encoded, e := EncodeCompoWayFCommand(command)
+	require.NoError(t, e)
+	assert.Equal(t, frame, encoded)
+	decoded, e := DecodeCompoWayFCommand(frame)
+	require.NoError(t, e)
+	assert.Equal(t, command, decoded)
+}
+
+func TestCompoWayFResponseFrame(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *CompoWayFResponse
+		frame    string
+	}{
+		{
+			name: "normal completion",
+			response: &CompoWayFResponse{Node: 1, RequestCode: CompoWayFReadVariables,
+				Data: []byte("000000FA")},
+			frame: "\x0201000001010000000000FA\x03\x05",
+		},
+		{
+			name:     "end code",
+			response: &CompoWayFResponse{Node: 1, EndCode: 0x14},
+			frame:    "\x02010014\x03\x07",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded, e := EncodeCompoWayFResponse(test.response)
+			require.NoError(t, e)
+			assert.Equal(t, []byte(test.frame), encoded)
+			decoded, e := DecodeCompoWayFResponse([]byte(test.frame))
+			require.NoError(t, e)
+			if len(test.response.Data) == 0 {
+				decoded.Data = nil
+			}
+			assert.Equal(t, test.response, decoded)
+		})
+	}
+}
+
+func TestCompoWayFFrameInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame string
+		err   error
+	}{
+		{"block check character", "\x02010014\x03\x08", ErrCompoWayFBCC},
+		{"no STX", "010014\x03\x00", ErrInvalidCompoWayFFrame},
+		{"no ETX", "\x02010014\x00", ErrInvalidCompoWayFFrame},
+		{"too short", "\x02\x03", ErrInvalidCompoWayFFrame},
+		{"node number", "\x02X10014\x03\x00", ErrInvalidCompoWayFFrame},
+		{"end code", "\x020100XX\x03\x00", ErrInvalidCompoWayFFrame},
+		{"response code missing", "\x02010000\x03\x00", ErrInvalidCompoWayFFrame},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frame := []byte(test.frame)
+			if test.err != ErrCompoWayFBCC && len(frame) > 2 {
+				frame[len(frame)-1] = compoWayFBCC(frame[1 : len(frame)-1])
+			}
+			_, e := DecodeCompoWayFResponse(frame)
+			assert.True(t, errors.Is(e, test.err), "error %v", e)
+		})
+	}
+
+	_, e := EncodeCompoWayFCommand(&CompoWayFCommand{Node: 100})
+	assert.True(t, errors.Is(e, ErrInvalidCompoWayFFrame))
+	_, e = EncodeCompoWayFResponse(&CompoWayFResponse{Node: 100})
+	assert.True(t, errors.Is(e, ErrInvalidCompoWayFFrame))
+}
+
+func TestCompoWayF(t *testing.T) {
+	var reply string
+	p, c := newTestClient(func(command *Payload) *Response {
+		return &Response{CommandCode: command.CommandCode, Data: []byte(reply)}
+	})
+	d := c.CompoWayF(SerialUnitPort(0, 1), 3)
+
+	// Four digits of variable types 8x are sign extended from 16 bits
+	reply = "03000001010000FFFF0064"
+	values, e := d.ReadVariables(0x81, 0x0003, 2)
+	require.NoError(t, e)
+	assert.Equal(t, []int32{-1, 100}, values)
+	assert.Equal(t, CommandCodeConvertToCompoWayFCommand, p.command.CommandCode)
+	assert.Equal(t, "030000101810003000002", string(p.command.Data))
+	assert.Equal(t, Address{Network: 0, Node: 10, Unit: 0x81}, p.header.dst)
+
+	reply = "03000001010000FFFFFF"
+	_, e = d.ReadVariables(0xc0, 0x0000, 1)
+	assert.True(t, errors.Is(e, ErrResponseTooShort), "error %v", e)
+	reply = "03000001010000FFFFFF9C"
+	values, e = d.ReadVariables(0xc0, 0x0000, 1)
+	require.NoError(t, e)
+	assert.Equal(t, []int32{-100}, values)
+
+	reply = "03000001020000"
+	require.NoError(t, d.WriteVariables(0xc1, 0x0010, []int32{-2, 300}))
+	assert.Equal(t, "030000102C10010000002FFFFFFFE0000012C", string(p.command.Data))
+	require.NoError(t, d.WriteVariables(0x81, 0x0010, []int32{-2}))
+	assert.Equal(t, "030000102810010000001FFFE", string(p.command.Data))
+
+	reply = "03000030050000"
+	require.NoError(t, d.OperationCommand(0x00, 0x00))
+	assert.Equal(t, "0300030050000", string(p.command.Data))
+
+	reply = "030013"
+	_, e = d.Execute(CompoWayFEchoback, nil)
+	var endCodeError *CompoWayFEndCodeError
+	require.True(t, errors.As(e, &endCodeError), "error %v", e)
+	assert.Equal(t, byte(0x13), endCodeError.EndCode)
+	assert.Equal(t, "CompoWay/F BCC error, end code 13", e.Error())
+
+	reply = "03000001011101"
+	_, e = d.ReadVariables(0x99, 0, 1)
+	var responseError *CompoWayFResponseError
+	require.True(t, errors.As(e, &responseError), "error %v", e)
+	assert.Equal(t, uint16(0x1101), responseError.ResponseCode)
+	assert.Equal(t, "CompoWay/F area type error, response code 1101", e.Error())
+}
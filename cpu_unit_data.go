@@ -0,0 +1,138 @@
+package fins
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// CPUUnitData Model, version and memory configuration of a CPU unit
+type CPUUnitData struct {
+	// Model CPU unit model, e.g. CJ2M-CPU33
+	Model string
+
+	// Version CPU unit internal system version
+	Version string
+
+	// DIPSwitches Settings of the CPU unit DIP switch, pin 1 in bit 0
+	DIPSwitches byte
+
+	// LargestEMBankNumber Number of the last extended memory bank
+	LargestEMBankNumber int
+
+	// ProgramAreaSize Size of the program area in steps
+	ProgramAreaSize int
+
+	// IOMSize Size of the IO memory area in bytes
+	IOMSize int
+
+	// DMWords Number of words in the DM area
+	DMWords int
+
+	// TimerCounterCount Number of timers and counters
+	TimerCounterCount int
+
+	// EMNonFileBanks Number of extended memory banks not used as file memory
+	EMNonFileBanks int
+
+	// MemoryCardType Type of the mounted memory card, 0 when none is mounted
+	MemoryCardType byte
+
+	// MemoryCardSize Size of the mounted memory card in bytes
+	MemoryCardSize int
+
+	// CPUBusUnits CPU bus units mounted to the CPU unit
+	CPUBusUnits []CPUBusUnit
+}
+
+// CPUBusUnit A CPU bus unit mounted to a CPU unit
+type CPUBusUnit struct {
+	UnitNumber int
+	ModelCode  uint16
+}
+
+const (
+	cpuUnitDataAreaDataLength   = 92
+	cpuUnitDataBusUnitsLength   = 156
+	cpuUnitDataBusUnitsPosition = 92
+)
+
+// ReadCPUUnitData Reads the model, version and memory configuration of the CPU unit
+func (c *Client) ReadCPUUnitData() (*CPUUnitData, error) {
+	command := &Payload{
+		CommandCode: CommandCodeCPUUnitDataRead,
+		Data:        []byte{},
+	}
+	r, e := c.execute(command)
+	if e != nil {
+		return nil, e
+	}
+
+	return decodeCPUUnitData(r.Data)
+}
+
+func decodeCPUUnitData(data []byte) (*CPUUnitData, error) {
+	if len(data) < cpuUnitDataAreaDataLength {
+		return nil, ErrResponseTooShort
+	}
+
+	d := &CPUUnitData{
+		Model:               decodeASCII(data[0:20]),
+		Version:             decodeASCII(data[20:40]),
+		DIPSwitches:         data[40],
+		LargestEMBankNumber: int(data[41]),
+		ProgramAreaSize:     int(binary.BigEndian.Uint16(data[80:82])) * 1024,
+		IOMSize:             int(data[82]) * 1024,
+		DMWords:             int(binary.BigEndian.Uint16(data[83:85])),
+		TimerCounterCount:   int(data[85]) * 1024,
+		EMNonFileBanks:      int(data[86]),
+		MemoryCardType:      data[89],
+		MemoryCardSize:      int(binary.BigEndian.Uint16(data[90:92])) * 1024,
+	}
+
+	if len(data) >= cpuUnitDataBusUnitsLength {
+		for i := 0; i < (cpuUnitDataBusUnitsLength-cpuUnitDataBusUnitsPosition)/2; i++ {
+			n := cpuUnitDataBusUnitsPosition + i*2
+			code := binary.BigEndian.Uint16(data[n : n+2])
+			if code != 0 {
+				d.CPUBusUnits = append(d.CPUBusUnits, CPUBusUnit{UnitNumber: i, ModelCode: code})
+			}
+		}
+	}
+
+	return d, nil
+}
+
+func encodeCPUUnitData(d *CPUUnitData) []byte {
+	data := make([]byte, cpuUnitDataBusUnitsLength)
+	copy(data[0:20], encodeASCII(d.Model, 20))
+	copy(data[20:40], encodeASCII(d.Version, 20))
+	data[40] = d.DIPSwitches
+	data[41] = byte(d.LargestEMBankNumber)
+	binary.BigEndian.PutUint16(data[80:82], uint16(d.ProgramAreaSize/1024))
+	data[82] = byte(d.IOMSize / 1024)
+	binary.BigEndian.PutUint16(data[83:85], uint16(d.DMWords))
+	data[85] = byte(d.TimerCounterCount / 1024)
+	data[86] = byte(d.EMNonFileBanks)
+	data[89] = d.MemoryCardType
+	binary.BigEndian.PutUint16(data[90:92], uint16(d.MemoryCardSize/1024))
+	for _, unit := range d.CPUBusUnits {
+		n := cpuUnitDataBusUnitsPosition + unit.UnitNumber*2
+		binary.BigEndian.PutUint16(data[n:n+2], unit.ModelCode)
+	}
+	return data
+}
+
+// decodeASCII Decodes fixed length ASCII text padded with spaces or NUL bytes
+func decodeASCII(data []byte) string {
+	if n := bytes.IndexByte(data, 0); n >= 0 {
+		data = data[:n]
+	}
+	return string(bytes.TrimRight(data, " "))
+}
+
+// encodeASCII Encodes text as fixed length ASCII padded with spaces
+func encodeASCII(s string, length int) []byte {
+	data := bytes.Repeat([]byte{' '}, length)
+	copy(data, s)
+	return data
+}
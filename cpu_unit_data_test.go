@@ -0,0 +1,51 @@
+package fins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUUnitData(t *testing.T) {
+	d := &CPUUnitData{
+		Model:               "CJ2M-CPU33",
+		Version:             "02.00",
+		DIPSwitches:         0x21,
+		LargestEMBankNumber: 3,
+		ProgramAreaSize:     60 * 1024,
+		IOMSize:             23 * 1024,
+		DMWords:             32768,
+		TimerCounterCount:   8 * 1024,
+		EMNonFileBanks:      4,
+		MemoryCardType:      0x04,
+		MemoryCardSize:      128 * 1024,
+		CPUBusUnits:         []CPUBusUnit{{UnitNumber: 0, ModelCode: 0x0300}, {UnitNumber: 15, ModelCode: 0x0101}},
+	}
+	data := encodeCPUUnitData(d)
+	assert.Equal(t, "CJ2M-CPU33          ", string(data[0:20]))
+	assert.Equal(t, []byte{0x00, 0x3c, 0x17, 0x80, 0x00, 0x08, 0x04}, data[80:87])
+	decoded, e := decodeCPUUnitData(data)
+	require.NoError(t, e)
+	assert.Equal(t, d, decoded)
+
+	// The CPU bus unit configuration is optional
+	decoded, e = decodeCPUUnitData(data[:cpuUnitDataAreaDataLength])
+	require.NoError(t, e)
+	assert.Nil(t, decoded.CPUBusUnits)
+	_, e = decodeCPUUnitData(data[:cpuUnitDataAreaDataLength-1])
+	assert.Equal(t, ErrResponseTooShort, e)
+}
+
+func TestReadCPUUnitData(t *testing.T) {
+	_, c := newSimulator(t)
+
+	d, e := c.ReadCPUUnitData()
+	require.NoError(t, e)
+	assert.Equal(t, "SIMULATOR", d.Model)
+	assert.Equal(t, "1.0", d.Version)
+	assert.Equal(t, 10*1024, d.ProgramAreaSize)
+	assert.Equal(t, 15*1024, d.IOMSize)
+	assert.Equal(t, 32768, d.DMWords)
+	assert.Nil(t, d.CPUBusUnits)
+}
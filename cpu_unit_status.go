@@ -0,0 +1,249 @@
+package fins
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// CPUStatus Program execution status of a CPU unit
+type CPUStatus byte
+
+const (
+	// CPUStatusStop CPU status: the program is not being executed
+	CPUStatusStop CPUStatus = 0x00
+
+	// CPUStatusRun CPU status: the program is being executed
+	CPUStatusRun CPUStatus = 0x01
+
+	// CPUStatusStandby CPU status: the CPU unit is on standby
+	CPUStatusStandby CPUStatus = 0x80
+)
+
+func (s CPUStatus) String() string {
+	switch s {
+	case CPUStatusStop:
+		return "stop"
+	case CPUStatusRun:
+		return "run"
+	case CPUStatusStandby:
+		return "standby"
+	}
+	return fmt.Sprintf("CPUStatus(0x%02x)", byte(s))
+}
+
+// FatalErrors Fatal error flags reported by a CPU unit
+type FatalErrors uint16
+
+const (
+	// FatalErrorFALS Fatal error: FALS instruction executed
+	FatalErrorFALS FatalErrors = 1 << 6
+
+	// FatalErrorCycleTimeTooLong Fatal error: cycle time exceeded the maximum cycle time
+	FatalErrorCycleTimeTooLong FatalErrors = 1 << 8
+
+	// FatalErrorProgram Fatal error: program error
+	FatalErrorProgram FatalErrors = 1 << 9
+
+	// FatalErrorIOSetting Fatal error: mounted units do not match the registered IO tables
+	FatalErrorIOSetting FatalErrors = 1 << 10
+
+	// FatalErrorTooManyIOPoints Fatal error: too many IO points
+	FatalErrorTooManyIOPoints FatalErrors = 1 << 11
+
+	// FatalErrorInnerBoard Fatal error: inner board fatal error
+	FatalErrorInnerBoard FatalErrors = 1 << 12
+
+	// FatalErrorDuplication Fatal error: duplicated unit number
+	FatalErrorDuplication FatalErrors = 1 << 13
+
+	// FatalErrorIOBus Fatal error: IO bus error
+	FatalErrorIOBus FatalErrors = 1 << 14
+
+	// FatalErrorMemory Fatal error: memory error
+	FatalErrorMemory FatalErrors = 1 << 15
+)
+
+var fatalErrorNames = []struct {
+	flag FatalErrors
+	name string
+}{
+	{FatalErrorFALS, "FALS error"},
+	{FatalErrorCycleTimeTooLong, "cycle time too long"},
+	{FatalErrorProgram, "program error"},
+	{FatalErrorIOSetting, "IO setting error"},
+	{FatalErrorTooManyIOPoints, "too many IO points"},
+	{FatalErrorInnerBoard, "inner board fatal error"},
+	{FatalErrorDuplication, "duplication error"},
+	{FatalErrorIOBus, "IO bus error"},
+	{FatalErrorMemory, "memory error"},
+}
+
+// Has Returns true if all of the given flags are set
+func (f FatalErrors) Has(flags FatalErrors) bool {
+	return f&flags == flags
+}
+
+// Names Returns the names of the flags that are set
+func (f FatalErrors) Names() []string {
+	names := make([]string, 0)
+	for _, n := range fatalErrorNames {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+func (f FatalErrors) String() string {
+	return strings.Join(f.Names(), ", ")
+}
+
+// NonFatalErrors Non-fatal error flags reported by a CPU unit
+type NonFatalErrors uint16
+
+const (
+	// NonFatalErrorSpecialIOUnitSetting Non-fatal error: special IO unit setting error
+	NonFatalErrorSpecialIOUnitSetting NonFatalErrors = 1 << 2
+
+	// NonFatalErrorCPUBusUnitSetting Non-fatal error: CPU bus unit setting error
+	NonFatalErrorCPUBusUnitSetting NonFatalErrors = 1 << 3
+
+	// NonFatalErrorBattery Non-fatal error: battery error
+	NonFatalErrorBattery NonFatalErrors = 1 << 4
+
+	// NonFatalErrorSYSMACBUS Non-fatal error: SYSMAC BUS error
+	NonFatalErrorSYSMACBUS NonFatalErrors = 1 << 5
+
+	// NonFatalErrorSpecialIOUnit Non-fatal error: special IO unit error
+	NonFatalErrorSpecialIOUnit NonFatalErrors = 1 << 6
+
+	// NonFatalErrorCPUBusUnit Non-fatal error: CPU bus unit error
+	NonFatalErrorCPUBusUnit NonFatalErrors = 1 << 7
+
+	// NonFatalErrorInnerBoard Non-fatal error: inner board error
+	NonFatalErrorInnerBoard NonFatalErrors = 1 << 8
+
+	// NonFatalErrorIOVerification Non-fatal error: mounted units do not match the registered IO tables
+	NonFatalErrorIOVerification NonFatalErrors = 1 << 9
+
+	// NonFatalErrorPLCSetup Non-fatal error: PLC setup error
+	NonFatalErrorPLCSetup NonFatalErrors = 1 << 10
+
+	// NonFatalErrorBasicIOUnit Non-fatal error: basic IO unit error
+	NonFatalErrorBasicIOUnit NonFatalErrors = 1 << 12
+
+	// NonFatalErrorInterruptTask Non-fatal error: interrupt task error
+	NonFatalErrorInterruptTask NonFatalErrors = 1 << 13
+
+	// NonFatalErrorFAL Non-fatal error: FAL instruction executed
+	NonFatalErrorFAL NonFatalErrors = 1 << 15
+)
+
+var nonFatalErrorNames = []struct {
+	flag NonFatalErrors
+	name string
+}{
+	{NonFatalErrorSpecialIOUnitSetting, "special IO unit setting error"},
+	{NonFatalErrorCPUBusUnitSetting, "CPU bus unit setting error"},
+	{NonFatalErrorBattery, "battery error"},
+	{NonFatalErrorSYSMACBUS, "SYSMAC BUS error"},
+	{NonFatalErrorSpecialIOUnit, "special IO unit error"},
+	{NonFatalErrorCPUBusUnit, "CPU bus unit error"},
+	{NonFatalErrorInnerBoard, "inner board error"},
+	{NonFatalErrorIOVerification, "IO verification error"},
+	{NonFatalErrorPLCSetup, "PLC setup error"},
+	{NonFatalErrorBasicIOUnit, "basic IO unit error"},
+	{NonFatalErrorInterruptTask, "interrupt task error"},
+	{NonFatalErrorFAL, "FAL error"},
+}
+
+// Has Returns true if all of the given flags are set
+func (f NonFatalErrors) Has(flags NonFatalErrors) bool {
+	return f&flags == flags
+}
+
+// Names Returns the names of the flags that are set
+func (f NonFatalErrors) Names() []string {
+	names := make([]string, 0)
+	for _, n := range nonFatalErrorNames {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+func (f NonFatalErrors) String() string {
+	return strings.Join(f.Names(), ", ")
+}
+
+// MessageFlags Flags for the eight messages of the MSG instruction, message 0 in bit 0
+type MessageFlags byte
+
+// Has Returns true if the flag for the given message number is set
+func (f MessageFlags) Has(number int) bool {
+	return number >= 0 && number < 8 && f&(1<<uint(number)) != 0
+}
+
+// CPUUnitStatus Operating status of a CPU unit
+type CPUUnitStatus struct {
+	Status         CPUStatus
+	Mode           OperatingMode
+	FatalErrors    FatalErrors
+	NonFatalErrors NonFatalErrors
+
+	// Messages Messages of the MSG instruction that exist
+	Messages MessageFlags
+
+	// ErrorCode Code of the most serious current error, 0 when there is none
+	ErrorCode uint16
+
+	// ErrorMessage Message of the FAL or FALS instruction that caused the current error
+	ErrorMessage string
+}
+
+const cpuUnitStatusLength = 26
+
+// ReadCPUUnitStatus Reads the operating status of the CPU unit
+func (c *Client) ReadCPUUnitStatus() (*CPUUnitStatus, error) {
+	command := &Payload{
+		CommandCode: CommandCodeCPUUnitStatusRead,
+		Data:        []byte{},
+	}
+	r, e := c.execute(command)
+	if e != nil {
+		return nil, e
+	}
+
+	return decodeCPUUnitStatus(r.Data)
+}
+
+func decodeCPUUnitStatus(data []byte) (*CPUUnitStatus, error) {
+	if len(data) < cpuUnitStatusLength {
+		return nil, ErrResponseTooShort
+	}
+
+	s := &CPUUnitStatus{
+		Status:         CPUStatus(data[0]),
+		Mode:           OperatingMode(data[1]),
+		FatalErrors:    FatalErrors(binary.BigEndian.Uint16(data[2:4])),
+		NonFatalErrors: NonFatalErrors(binary.BigEndian.Uint16(data[4:6])),
+		Messages:       MessageFlags(binary.BigEndian.Uint16(data[6:8])),
+		ErrorCode:      binary.BigEndian.Uint16(data[8:10]),
+		ErrorMessage:   decodeASCII(data[10:26]),
+	}
+	return s, nil
+}
+
+func encodeCPUUnitStatus(s *CPUUnitStatus) []byte {
+	data := make([]byte, cpuUnitStatusLength)
+	data[0] = byte(s.Status)
+	data[1] = byte(s.Mode)
+	binary.BigEndian.PutUint16(data[2:4], uint16(s.FatalErrors))
+	binary.BigEndian.PutUint16(data[4:6], uint16(s.NonFatalErrors))
+	binary.BigEndian.PutUint16(data[6:8], uint16(s.Messages))
+	binary.BigEndian.PutUint16(data[8:10], s.ErrorCode)
+	copy(data[10:26], encodeASCII(s.ErrorMessage, 16))
+	return data
+}
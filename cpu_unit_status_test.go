@@ -0,0 +1,66 @@
+package fins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUUnitStatus(t *testing.T) {
+	s := &CPUUnitStatus{
+		Status:         CPUStatusRun,
+		Mode:           OperatingModeMonitor,
+		FatalErrors:    FatalErrorFALS | FatalErrorMemory,
+		NonFatalErrors: NonFatalErrorBattery,
+		Messages:       0x05,
+		ErrorCode:      0x4101,
+		ErrorMessage:   "LOW PRESSURE",
+	}
+	data := encodeCPUUnitStatus(s)
+	assert.Equal(t, []byte{0x01, 0x02, 0x80, 0x40, 0x00, 0x10, 0x00, 0x05, 0x41, 0x01}, data[0:10])
+	decoded, e := decodeCPUUnitStatus(data)
+	require.NoError(t, e)
+	assert.Equal(t, s, decoded)
+
+	_, e = decodeCPUUnitStatus(data[:cpuUnitStatusLength-1])
+	assert.Equal(t, ErrResponseTooShort, e)
+}
+
+func TestCPUUnitStatusFlags(t *testing.T) {
+	fatal := FatalErrorCycleTimeTooLong | FatalErrorIOBus
+	assert.True(t, fatal.Has(FatalErrorIOBus))
+	assert.False(t, fatal.Has(FatalErrorIOBus|FatalErrorMemory))
+	assert.Equal(t, "cycle time too long, IO bus error", fatal.String())
+	assert.Equal(t, []string{}, FatalErrors(0).Names())
+
+	nonFatal := NonFatalErrorFAL | NonFatalErrorSpecialIOUnitSetting
+	assert.Equal(t, []string{"special IO unit setting error", "FAL error"}, nonFatal.Names())
+
+	messages := MessageFlags(0x81)
+	assert.True(t, messages.Has(0))
+	assert.True(t, messages.Has(7))
+	assert.False(t, messages.Has(1))
+	assert.False(t, messages.Has(8))
+
+	assert.Equal(t, "standby", CPUStatusStandby.String())
+	assert.Equal(t, "CPUStatus(0x02)", CPUStatus(0x02).String())
+}
+
+func TestReadCPUUnitStatus(t *testing.T) {
+	s, c := newSimulator(t)
+
+	status, e := c.ReadCPUUnitStatus()
+	require.NoError(t, e)
+	assert.Equal(t, &CPUUnitStatus{Status: CPUStatusStop, Mode: OperatingModeProgram}, status)
+
+	require.NoError(t, c.Run())
+	s.SetMessage(2, "CHECK VALVE")
+	s.RaiseError(0x4101, 0)
+	status, e = c.ReadCPUUnitStatus()
+	require.NoError(t, e)
+	assert.Equal(t, CPUStatusRun, status.Status)
+	assert.Equal(t, OperatingModeRun, status.Mode)
+	assert.Equal(t, MessageFlags(0x04), status.Messages)
+	assert.Equal(t, uint16(0x4101), status.ErrorCode)
+}
@@ -0,0 +1,53 @@
+package fins
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// CycleTime Average, maximum and minimum cycle times measured by a CPU unit since the statistics were initialized
+type CycleTime struct {
+	Average time.Duration
+	Max     time.Duration
+	Min     time.Duration
+}
+
+const (
+	cycleTimeInitialize byte = 0x00
+	cycleTimeRead       byte = 0x01
+
+	// cycleTimeUnit Resolution of the cycle times reported by a CPU unit
+	cycleTimeUnit = 100 * time.Microsecond
+)
+
+// ResetCycleTime Initializes the cycle time statistics of the CPU unit
+func (c *Client) ResetCycleTime() error {
+	_, e := c.execute(cycleTimeCommand(cycleTimeInitialize))
+	return e
+}
+
+// ReadCycleTime Reads the cycle time statistics of the CPU unit
+func (c *Client) ReadCycleTime() (*CycleTime, error) {
+	r, e := c.execute(cycleTimeCommand(cycleTimeRead))
+	if e != nil {
+		return nil, e
+	}
+	if len(r.Data) < 12 {
+		return nil, ErrResponseTooShort
+	}
+
+	t := &CycleTime{
+		Average: time.Duration(binary.BigEndian.Uint32(r.Data[0:4])) * cycleTimeUnit,
+		Max:     time.Duration(binary.BigEndian.Uint32(r.Data[4:8])) * cycleTimeUnit,
+		Min:     time.Duration(binary.BigEndian.Uint32(r.Data[8:12])) * cycleTimeUnit,
+	}
+	return t, nil
+}
+
+func cycleTimeCommand(parameter byte) *Payload {
+	p := &Payload{
+		CommandCode: CommandCodeCycleTimeRead,
+		Data:        []byte{parameter},
+	}
+	return p
+}
@@ -0,0 +1,166 @@
+package fins
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CycleTimeSample A cycle time reading taken by a CycleTimeSampler
+type CycleTimeSample struct {
+	Time time.Time
+	CycleTime
+}
+
+// CycleTimeRegression Cycle time statistics that exceed the baseline by more than the sampler tolerance
+type CycleTimeRegression struct {
+	Baseline CycleTime
+	Current  CycleTime
+}
+
+// CycleTimeSampler Tracks the cycle time of a CPU unit over time and flags regressions against a baseline, such as
+// after a program download
+type CycleTimeSampler struct {
+	client      *Client
+	historySize int
+	tolerance   float64
+	samples     []CycleTimeSample
+	baseline    *CycleTime
+
+	sync.Mutex
+}
+
+// NewCycleTimeSampler Creates a sampler keeping up to historySize samples, at least 1. A regression is flagged when
+// the average or maximum cycle time exceeds the baseline by more than tolerance, e.g. 0.1 for 10%
+func NewCycleTimeSampler(client *Client, historySize int, tolerance float64) *CycleTimeSampler {
+	if historySize < 1 {
+		historySize = 1
+	}
+	s := new(CycleTimeSampler)
+	s.client = client
+	s.historySize = historySize
+	s.tolerance = tolerance
+	return s
+}
+
+// Sample Reads the cycle time of the CPU unit and records it
+func (s *CycleTimeSampler) Sample() (*CycleTimeSample, error) {
+	t, e := s.client.ReadCycleTime()
+	if e != nil {
+		return nil, e
+	}
+	sample := CycleTimeSample{
+		Time:      time.Now(),
+		CycleTime: *t,
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.samples = append(s.samples, sample)
+	if len(s.samples) > s.historySize {
+		s.samples = s.samples[len(s.samples)-s.historySize:]
+	}
+	return &sample, nil
+}
+
+// Samples Returns the recorded samples, oldest first
+func (s *CycleTimeSampler) Samples() []CycleTimeSample {
+	s.Lock()
+	defer s.Unlock()
+	return append([]CycleTimeSample(nil), s.samples...)
+}
+
+// Statistics Returns the mean of the average cycle times and the extremes of the maximum and minimum cycle times
+// over the recorded samples, and false if there are none
+func (s *CycleTimeSampler) Statistics() (CycleTime, bool) {
+	s.Lock()
+	defer s.Unlock()
+	return s.statistics()
+}
+
+func (s *CycleTimeSampler) statistics() (CycleTime, bool) {
+	if len(s.samples) == 0 {
+		return CycleTime{}, false
+	}
+
+	t := CycleTime{Min: s.samples[0].Min}
+	var sum time.Duration
+	for _, sample := range s.samples {
+		sum += sample.Average
+		if sample.Max > t.Max {
+			t.Max = sample.Max
+		}
+		if sample.Min < t.Min {
+			t.Min = sample.Min
+		}
+	}
+	t.Average = sum / time.Duration(len(s.samples))
+	return t, true
+}
+
+// SetBaseline Makes the statistics of the recorded samples the baseline, then discards the samples and initializes
+// the cycle time statistics of the CPU unit so that subsequent samples are compared against the baseline
+func (s *CycleTimeSampler) SetBaseline() error {
+	s.Lock()
+	if t, ok := s.statistics(); ok {
+		s.baseline = &t
+	}
+	s.samples = nil
+	s.Unlock()
+
+	return s.client.ResetCycleTime()
+}
+
+// Baseline Returns the baseline, and false if none has been set
+func (s *CycleTimeSampler) Baseline() (CycleTime, bool) {
+	s.Lock()
+	defer s.Unlock()
+	if s.baseline == nil {
+		return CycleTime{}, false
+	}
+	return *s.baseline, true
+}
+
+// Regression Returns the regression of the recorded samples against the baseline, or nil if there is none
+func (s *CycleTimeSampler) Regression() *CycleTimeRegression {
+	s.Lock()
+	defer s.Unlock()
+	if s.baseline == nil {
+		return nil
+	}
+	current, ok := s.statistics()
+	if !ok {
+		return nil
+	}
+
+	limit := 1 + s.tolerance
+	if float64(current.Average) > float64(s.baseline.Average)*limit ||
+		float64(current.Max) > float64(s.baseline.Max)*limit {
+		return &CycleTimeRegression{
+			Baseline: *s.baseline,
+			Current:  current,
+		}
+	}
+	return nil
+}
+
+// Run Samples the cycle time every interval until the context is done or a read fails, calling onRegression
+// after each sample that shows a regression
+func (s *CycleTimeSampler) Run(ctx context.Context, interval time.Duration, onRegression func(r *CycleTimeRegression)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, e := s.Sample(); e != nil {
+				return e
+			}
+			if r := s.Regression(); r != nil && onRegression != nil {
+				onRegression(r)
+			}
+		}
+	}
+}
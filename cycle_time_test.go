@@ -0,0 +1,92 @@
+package fins
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCycleTime(t *testing.T) {
+	s, c := newSimulator(t)
+
+	s.SetCycleTime(CycleTime{Average: 1500 * time.Microsecond, Max: 4 * time.Millisecond, Min: time.Millisecond})
+	cycle, e := c.ReadCycleTime()
+	require.NoError(t, e)
+	assert.Equal(t, &CycleTime{Average: 1500 * time.Microsecond, Max: 4 * time.Millisecond, Min: time.Millisecond},
+		cycle)
+
+	require.NoError(t, c.ResetCycleTime())
+	cycle, e = c.ReadCycleTime()
+	require.NoError(t, e)
+	assert.Equal(t, &CycleTime{Average: 1500 * time.Microsecond, Max: 1500 * time.Microsecond,
+		Min: 1500 * time.Microsecond}, cycle)
+}
+
+func TestCycleTimeSampler(t *testing.T) {
+	s, c := newSimulator(t)
+	sampler := NewCycleTimeSampler(c, 2, 0.1)
+
+	_, ok := sampler.Statistics()
+	assert.False(t, ok)
+	assert.Nil(t, sampler.Regression())
+
+	for _, average := range []time.Duration{5, 1, 2, 3} {
+		s.SetCycleTime(CycleTime{Average: average * time.Millisecond, Max: 2 * average * time.Millisecond,
+			Min: average * time.Millisecond / 2})
+		sample, e := sampler.Sample()
+		require.NoError(t, e)
+		assert.Equal(t, average*time.Millisecond, sample.Average)
+	}
+
+	// Only the last two samples are kept
+	samples := sampler.Samples()
+	require.Len(t, samples, 2)
+	assert.Equal(t, 2*time.Millisecond, samples[0].Average)
+	statistics, ok := sampler.Statistics()
+	require.True(t, ok)
+	assert.Equal(t, CycleTime{Average: 2500 * time.Microsecond, Max: 6 * time.Millisecond, Min: time.Millisecond},
+		statistics)
+
+	require.NoError(t, sampler.SetBaseline())
+	baseline, ok := sampler.Baseline()
+	require.True(t, ok)
+	assert.Equal(t, statistics, baseline)
+	assert.Empty(t, sampler.Samples())
+
+	// Within the tolerance, then beyond it
+	s.SetCycleTime(CycleTime{Average: 2700 * time.Microsecond, Max: 6 * time.Millisecond})
+	_, e := sampler.Sample()
+	require.NoError(t, e)
+	assert.Nil(t, sampler.Regression())
+	s.SetCycleTime(CycleTime{Average: 2700 * time.Microsecond, Max: 8 * time.Millisecond})
+	_, e = sampler.Sample()
+	require.NoError(t, e)
+	regression := sampler.Regression()
+	require.NotNil(t, regression)
+	assert.Equal(t, baseline, regression.Baseline)
+	assert.Equal(t, 8*time.Millisecond, regression.Current.Max)
+}
+
+func TestCycleTimeSamplerRun(t *testing.T) {
+	s, c := newSimulator(t)
+	sampler := NewCycleTimeSampler(c, 10, 0)
+	s.SetCycleTime(CycleTime{Average: time.Millisecond, Max: time.Millisecond, Min: time.Millisecond})
+	_, e := sampler.Sample()
+	require.NoError(t, e)
+	require.NoError(t, sampler.SetBaseline())
+	s.SetCycleTime(CycleTime{Average: 2 * time.Millisecond, Max: 2 * time.Millisecond, Min: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var regression *CycleTimeRegression
+	e = sampler.Run(ctx, time.Millisecond, func(r *CycleTimeRegression) {
+		regression = r
+		cancel()
+	})
+	assert.True(t, errors.Is(e, context.Canceled), "error %v", e)
+	require.NotNil(t, regression)
+	assert.Equal(t, 2*time.Millisecond, regression.Current.Average)
+}
@@ -36,6 +36,15 @@ func encodeIOAddress(ioAddr IOAddress) []byte {
 	return bytes
 }
 
+func decodeIOAddress(bytes []byte) IOAddress {
+	ioAddr := IOAddress{
+		MemoryArea: bytes[0],
+		Address:    binary.BigEndian.Uint16(bytes[1:3]),
+		BitOffset:  bytes[3],
+	}
+	return ioAddr
+}
+
 func decodeFrame(bytes []byte) *Frame {
 	frame := &Frame{
 		Header:  decodeHeader(bytes[:10]),
@@ -94,8 +103,11 @@ func encodePayload(payload *Payload) []byte {
 	return bytes
 }
 
-var errBCDBadDigit = errors.New("bad digit in BCD decoding")
-var errBCDOverflow = errors.New("overflow occurred in BCD decoding")
+// ErrBCDBadDigit Error when a BCD value has a nibble that is not a decimal digit
+var ErrBCDBadDigit = errors.New("bad digit in BCD decoding")
+
+// ErrBCDOverflow Error when a value has more digits than its BCD encoding holds
+var ErrBCDOverflow = errors.New("overflow occurred in BCD decoding")
 
 func encodeBCD(x uint64) []byte {
 	if x == 0 {
@@ -120,10 +132,32 @@ func encodeBCD(x uint64) []byte {
 	return bcd
 }
 
+// encodePackedBCD Encodes x as BCD in exactly size bytes, most significant digit first
+func encodePackedBCD(x uint64, size int) ([]byte, error) {
+	bcd := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		bcd[i] = byte((x/10)%10)<<4 | byte(x%10)
+		x = x / 100
+	}
+	if x != 0 {
+		return nil, ErrBCDOverflow
+	}
+	return bcd, nil
+}
+
+// decodePackedBCD Decodes BCD in which every nibble is a digit, as opposed to decodeBCD which accepts a terminating
+// 0x0f nibble
+func decodePackedBCD(bcd []byte) (uint64, error) {
+	if len(bcd) > 0 && bcd[len(bcd)-1]&0x0f == 0x0f {
+		return 0, ErrBCDBadDigit
+	}
+	return decodeBCD(bcd)
+}
+
 func timesTenPlusCatchingOverflow(x uint64, digit uint64) (uint64, error) {
 	x5 := x<<2 + x
 	if int64(x5) < 0 || x5<<1 > ^digit {
-		return 0, errBCDOverflow
+		return 0, ErrBCDOverflow
 	}
 	return x5<<1 + digit, nil
 }
@@ -132,7 +166,7 @@ func decodeBCD(bcd []byte) (x uint64, err error) {
 	for i, b := range bcd {
 		hi, lo := uint64(b>>4), uint64(b&0x0f)
 		if hi > 9 {
-			return 0, errBCDBadDigit
+			return 0, ErrBCDBadDigit
 		}
 		x, err = timesTenPlusCatchingOverflow(x, hi)
 		if err != nil {
@@ -142,7 +176,7 @@ func decodeBCD(bcd []byte) (x uint64, err error) {
 			return x, nil
 		}
 		if lo > 9 {
-			return 0, errBCDBadDigit
+			return 0, ErrBCDBadDigit
 		}
 		x, err = timesTenPlusCatchingOverflow(x, lo)
 		if err != nil {
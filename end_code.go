@@ -1,5 +1,7 @@
 package fins
 
+import "fmt"
+
 // Data taken from Omron document Cat. No. W342-E1-15, pages 155-161
 const (
 	// EndCodeNormalCompletion End code: normal completion
@@ -257,3 +259,16 @@ const (
 	// EndCodeAbortServiceAborted End code: abort; service aborted
 	EndCodeAbortServiceAborted uint16 = 0x4001
 )
+
+// endCodeFlagsMask Bits of an end code that report network relay and CPU unit error status rather than the
+// completion status of the command; the response is still valid when only these are set
+const endCodeFlagsMask uint16 = 0x80c0
+
+// EndCodeError Error when the destination reports an end code other than normal completion
+type EndCodeError struct {
+	EndCode uint16
+}
+
+func (e *EndCodeError) Error() string {
+	return fmt.Sprintf("error reported by destination, end code 0x%x", e.EndCode)
+}
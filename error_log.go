@@ -0,0 +1,188 @@
+package fins
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+const (
+	// ErrorClearAll Error code clearing all current errors
+	ErrorClearAll uint16 = 0xffff
+
+	// ErrorClearCurrent Error code clearing the current error of highest priority
+	ErrorClearCurrent uint16 = 0xfffe
+
+	errorLogRecordLength = 10
+)
+
+// ErrorLogRecord A record of the CPU unit error log
+type ErrorLogRecord struct {
+	// Code Error code, see CPUErrorDescription
+	Code uint16
+
+	// Details Error details, the meaning of which depends on the error code
+	Details uint16
+
+	// Time Time at which the error occurred according to the PLC clock
+	Time time.Time
+}
+
+// Description Returns a description of the error code
+func (r *ErrorLogRecord) Description() string {
+	return CPUErrorDescription(r.Code)
+}
+
+// ErrorLog Records read from the CPU unit error log
+type ErrorLog struct {
+	// Capacity Largest number of records the CPU unit keeps
+	Capacity int
+
+	// Stored Number of records stored in the CPU unit
+	Stored int
+
+	// Records Records read, oldest first
+	Records []ErrorLogRecord
+}
+
+// ReadErrorLog Reads count records from the CPU unit error log starting at record number first, where record 0 is
+// the oldest. The CPU unit reads at most 20 records per command
+func (c *Client) ReadErrorLog(first uint16, count uint16) (*ErrorLog, error) {
+	log, e := c.readLogRecords(CommandCodeErrorLogRead, errorLogRecordLength, first, count)
+	if e != nil {
+		return nil, e
+	}
+	return decodeErrorLog(log, c.Location())
+}
+
+// ReadAllErrorLog Reads every record stored in the CPU unit error log
+func (c *Client) ReadAllErrorLog() (*ErrorLog, error) {
+	log, e := c.readAllLogRecords(CommandCodeErrorLogRead, errorLogRecordLength)
+	if e != nil {
+		return nil, e
+	}
+	return decodeErrorLog(log, c.Location())
+}
+
+// ClearError Clears the current error with the given error code, or ErrorClearAll or ErrorClearCurrent
+func (c *Client) ClearError(code uint16) error {
+	command := &Payload{
+		CommandCode: CommandCodeErrorClear,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(command.Data, code)
+	_, e := c.execute(command)
+	return e
+}
+
+// ClearAllErrors Clears all current errors
+func (c *Client) ClearAllErrors() error {
+	return c.ClearError(ErrorClearAll)
+}
+
+// ClearErrorLog Clears the CPU unit error log
+func (c *Client) ClearErrorLog() error {
+	command := &Payload{
+		CommandCode: CommandCodeErrorLogClear,
+		Data:        []byte{},
+	}
+	_, e := c.execute(command)
+	return e
+}
+
+func decodeErrorLog(log *logRecords, location *time.Location) (*ErrorLog, error) {
+	errorLog := &ErrorLog{
+		Capacity: log.capacity,
+		Stored:   log.stored,
+		Records:  make([]ErrorLogRecord, len(log.records)),
+	}
+	for i, record := range log.records {
+		t, e := decodeLogTime(record[4:10], location)
+		if e != nil {
+			return nil, e
+		}
+		errorLog.Records[i] = ErrorLogRecord{
+			Code:    binary.BigEndian.Uint16(record[0:2]),
+			Details: binary.BigEndian.Uint16(record[2:4]),
+			Time:    t,
+		}
+	}
+	return errorLog, nil
+}
+
+func encodeErrorLogRecord(r *ErrorLogRecord) []byte {
+	data := make([]byte, errorLogRecordLength)
+	binary.BigEndian.PutUint16(data[0:2], r.Code)
+	binary.BigEndian.PutUint16(data[2:4], r.Details)
+	copy(data[4:10], encodeLogTime(r.Time))
+	return data
+}
+
+// decodeLogTime Decodes a log time stamp held in BCD as minute, second, day, hour, year and month
+func decodeLogTime(data []byte, location *time.Location) (time.Time, error) {
+	clock, e := decodeClock([]byte{data[4], data[5], data[2], data[3], data[0], data[1]}, location)
+	if e != nil {
+		return time.Time{}, e
+	}
+	return clock.Time, nil
+}
+
+func encodeLogTime(t time.Time) []byte {
+	clock, e := encodeClock(t)
+	if e != nil {
+		return make([]byte, 6)
+	}
+	return []byte{clock[4], clock[5], clock[2], clock[3], clock[0], clock[1]}
+}
+
+// cpuErrorCatalog Descriptions of CPU unit error codes; where numbered is set, the error code less first is the
+// number of the named item
+var cpuErrorCatalog = []struct {
+	first       uint16
+	last        uint16
+	description string
+	numbered    string
+}{
+	{0x008b, 0x008b, "interrupt task error", ""},
+	{0x009a, 0x009a, "basic IO unit error", ""},
+	{0x009b, 0x009b, "PLC setup error", ""},
+	{0x00e7, 0x00e7, "IO verification error", ""},
+	{0x00f7, 0x00f7, "battery error", ""},
+	{0x0200, 0x020f, "CPU bus unit error", "unit"},
+	{0x02f0, 0x02f0, "inner board error", ""},
+	{0x0300, 0x035f, "special IO unit error", "unit"},
+	{0x0400, 0x040f, "CPU bus unit setting error", "unit"},
+	{0x0500, 0x055f, "special IO unit setting error", "unit"},
+	{0x4100, 0x42ff, "FAL error", "FAL"},
+	{0x809f, 0x809f, "cycle time too long", ""},
+	{0x80c0, 0x80c7, "IO bus error", "rack"},
+	{0x80ce, 0x80cf, "IO bus error; end cover missing", ""},
+	{0x80e0, 0x80e0, "IO setting error", ""},
+	{0x80e1, 0x80e1, "too many IO points", ""},
+	{0x80e9, 0x80ea, "duplication error", ""},
+	{0x80f0, 0x80f0, "program error", ""},
+	{0x80f1, 0x80f1, "memory error", ""},
+	{0xc100, 0xc2ff, "FALS error", "FALS"},
+}
+
+// CPUErrorDescription Returns a description of a CPU unit error code as found in the error log and CPU unit status
+func CPUErrorDescription(code uint16) string {
+	for _, e := range cpuErrorCatalog {
+		if code < e.first || code > e.last {
+			continue
+		}
+		if e.numbered != "" {
+			return fmt.Sprintf("%s; %s %d", e.description, e.numbered, code-e.first)
+		}
+		return e.description
+	}
+	return fmt.Sprintf("unknown error 0x%04x", code)
+}
+
+// ErrorDescription Returns a description of the current error code
+func (s *CPUUnitStatus) ErrorDescription() string {
+	if s.ErrorCode == 0 {
+		return ""
+	}
+	return CPUErrorDescription(s.ErrorCode)
+}
@@ -0,0 +1,107 @@
+package fins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUErrorDescription(t *testing.T) {
+	tests := []struct {
+		code        uint16
+		description string
+	}{
+		{0x00f7, "battery error"},
+		{0x0203, "CPU bus unit error; unit 3"},
+		{0x4101, "FAL error; FAL 1"},
+		{0x42ff, "FAL error; FAL 511"},
+		{0x80c7, "IO bus error; rack 7"},
+		{0xc10a, "FALS error; FALS 10"},
+		{0x1234, "unknown error 0x1234"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.description, CPUErrorDescription(test.code), "0x%04x", test.code)
+	}
+
+	assert.Equal(t, "", (&CPUUnitStatus{}).ErrorDescription())
+	assert.Equal(t, "memory error", (&CPUUnitStatus{ErrorCode: 0x80f1}).ErrorDescription())
+	assert.Equal(t, "program error", (&ErrorLogRecord{Code: 0x80f0}).Description())
+}
+
+func TestErrorLogRecord(t *testing.T) {
+	r := &ErrorLogRecord{Code: 0x80c1, Details: 0x0102, Time: time.Date(2024, time.March, 5, 17, 8, 9, 0, time.UTC)}
+	data := encodeErrorLogRecord(r)
+	// The time stamp holds minute, second, day, hour, year and month
+	assert.Equal(t, []byte{0x80, 0xc1, 0x01, 0x02, 0x08, 0x09, 0x05, 0x17, 0x24, 0x03}, data)
+
+	response := append([]byte{0x00, 0x14, 0x00, 0x01, 0x00, 0x01}, data...)
+	log, e := decodeLogRecords(response, errorLogRecordLength)
+	require.NoError(t, e)
+	errorLog, e := decodeErrorLog(log, time.UTC)
+	require.NoError(t, e)
+	assert.Equal(t, &ErrorLog{Capacity: 20, Stored: 1, Records: []ErrorLogRecord{*r}}, errorLog)
+
+	_, e = decodeLogRecords(response[:len(response)-1], errorLogRecordLength)
+	assert.Equal(t, ErrResponseTooShort, e)
+}
+
+func TestErrorLog(t *testing.T) {
+	s, c := newSimulator(t)
+
+	errorLog, e := c.ReadAllErrorLog()
+	require.NoError(t, e)
+	assert.Equal(t, &ErrorLog{Capacity: serverErrorLogCapacity, Records: []ErrorLogRecord{}}, errorLog)
+
+	// The oldest records are dropped once the log is full
+	raised := time.Now()
+	for i := 0; i < 25; i++ {
+		s.RaiseError(0x4100+uint16(i), uint16(i))
+	}
+	errorLog, e = c.ReadAllErrorLog()
+	require.NoError(t, e)
+	assert.Equal(t, serverErrorLogCapacity, errorLog.Stored)
+	require.Len(t, errorLog.Records, serverErrorLogCapacity)
+	for i, r := range errorLog.Records {
+		assert.Equal(t, 0x4105+uint16(i), r.Code)
+		assert.Equal(t, 5+uint16(i), r.Details)
+		assert.WithinDuration(t, raised, r.Time, 2*time.Second)
+	}
+
+	errorLog, e = c.ReadErrorLog(18, 5)
+	require.NoError(t, e)
+	require.Len(t, errorLog.Records, 2)
+	assert.Equal(t, "FAL error; FAL 23", errorLog.Records[0].Description())
+
+	require.NoError(t, c.ClearErrorLog())
+	errorLog, e = c.ReadErrorLog(0, 20)
+	require.NoError(t, e)
+	assert.Equal(t, 0, errorLog.Stored)
+	assert.Empty(t, errorLog.Records)
+}
+
+func TestClearError(t *testing.T) {
+	s, c := newSimulator(t)
+	for _, code := range []uint16{0x00f7, 0x4101, 0x4102, 0x009b} {
+		s.RaiseError(code, 0)
+	}
+	currentError := func() uint16 {
+		status, e := c.ReadCPUUnitStatus()
+		require.NoError(t, e)
+		return status.ErrorCode
+	}
+
+	require.Equal(t, uint16(0x009b), currentError())
+	require.NoError(t, c.ClearError(ErrorClearCurrent))
+	assert.Equal(t, uint16(0x4102), currentError())
+	require.NoError(t, c.ClearError(0x4102))
+	assert.Equal(t, uint16(0x4101), currentError())
+	require.NoError(t, c.ClearAllErrors())
+	assert.Equal(t, uint16(0), currentError())
+
+	// Clearing errors keeps the error log
+	errorLog, e := c.ReadAllErrorLog()
+	require.NoError(t, e)
+	assert.Len(t, errorLog.Records, 4)
+}
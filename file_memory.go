@@ -0,0 +1,656 @@
+package fins
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DiskMemoryCard Disk number: memory card
+	DiskMemoryCard uint16 = 0x8000
+
+	// DiskEMFileMemory Disk number: extended memory used as file memory
+	DiskEMFileMemory uint16 = 0x8001
+)
+
+const (
+	fileNameLength = 12
+	fileDataLength = 20
+	diskDataLength = 26
+
+	// fileNameReadMax Largest number of directory entries read in one command
+	fileNameReadMax = 40
+
+	// fileTransferMax Largest number of bytes of file data transferred in one command
+	fileTransferMax = 990
+
+	fileLastFlag uint16 = 0x8000
+
+	// fileAttributeDirectory Bit of the attribute byte of a directory entry marking a directory
+	fileAttributeDirectory byte = 0x10
+
+	fileWriteNew       uint16 = 0x0000
+	fileWriteOverwrite uint16 = 0x0001
+	fileWriteAppend    uint16 = 0x0002
+	fileWriteReplace   uint16 = 0x0003
+
+	directoryCreate uint16 = 0x0000
+	directoryDelete uint16 = 0x0001
+)
+
+// DiskData Volume label and capacity of a file device
+type DiskData struct {
+	VolumeLabel string
+	Time        time.Time
+
+	// Capacity Total capacity in bytes
+	Capacity int64
+
+	// Free Unused capacity in bytes
+	Free int64
+}
+
+// FileMemory The memory card or EM file memory of a CPU unit as a file system for use with io/fs. File and directory
+// names are limited to the 8.3 format and are not case sensitive
+type FileMemory struct {
+	client *Client
+	disk   uint16
+}
+
+var (
+	_ fs.ReadDirFS  = (*FileMemory)(nil)
+	_ fs.ReadFileFS = (*FileMemory)(nil)
+	_ fs.StatFS     = (*FileMemory)(nil)
+)
+
+// FileMemory Returns the file memory on the given disk, DiskMemoryCard or DiskEMFileMemory
+func (c *Client) FileMemory(disk uint16) *FileMemory {
+	f := new(FileMemory)
+	f.client = c
+	f.disk = disk
+	return f
+}
+
+// Open Opens the named file or directory for reading
+func (f *FileMemory) Open(name string) (fs.File, error) {
+	info, e := f.stat("open", name)
+	if e != nil {
+		return nil, e
+	}
+	if info.IsDir() {
+		return &fileMemoryDirectory{fm: f, name: name, info: info}, nil
+	}
+	return &fileMemoryFile{fm: f, name: name, info: info}, nil
+}
+
+// Stat Returns the file information of the named file or directory
+func (f *FileMemory) Stat(name string) (fs.FileInfo, error) {
+	return f.stat("stat", name)
+}
+
+// ReadDir Reads the named directory, returning its entries sorted by file name
+func (f *FileMemory) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	_, infos, e := f.readDirectory(plcDirectory(name))
+	if e != nil {
+		return nil, fileError("readdir", name, e)
+	}
+
+	entries := make([]fs.DirEntry, len(infos))
+	for i, info := range infos {
+		entries[i] = info
+	}
+	return entries, nil
+}
+
+// ReadFile Reads the named file
+func (f *FileMemory) ReadFile(name string) ([]byte, error) {
+	dir, file, e := splitFilePath("readfile", name)
+	if e != nil {
+		return nil, e
+	}
+
+	var data []byte
+	for {
+		size, chunk, e := f.readFileChunk(dir, file, int64(len(data)), fileTransferMax)
+		if e != nil {
+			return nil, fileError("readfile", name, e)
+		}
+		data = append(data, chunk...)
+		if int64(len(data)) >= size || len(chunk) == 0 {
+			return data, nil
+		}
+	}
+}
+
+// WriteFile Creates the named file, or replaces it if it exists, with the given data
+func (f *FileMemory) WriteFile(name string, data []byte) error {
+	dir, file, e := splitFilePath("writefile", name)
+	if e != nil {
+		return e
+	}
+
+	parameter := fileWriteReplace
+	for position := 0; ; {
+		n := len(data) - position
+		if n > fileTransferMax {
+			n = fileTransferMax
+		}
+		e := f.writeFileChunk(parameter, dir, file, int64(position), data[position:position+n])
+		if e != nil {
+			return fileError("writefile", name, e)
+		}
+		position += n
+		if position >= len(data) {
+			return nil
+		}
+		parameter = fileWriteAppend
+	}
+}
+
+// Remove Deletes the named file
+func (f *FileMemory) Remove(name string) error {
+	dir, file, e := splitFilePath("remove", name)
+	if e != nil {
+		return e
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeFileDelete,
+		Data:        make([]byte, 4),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+	binary.BigEndian.PutUint16(command.Data[2:4], 1)
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, file...)
+	r, e := f.client.execute(command)
+	if e != nil {
+		return fileError("remove", name, e)
+	}
+	if len(r.Data) >= 2 && binary.BigEndian.Uint16(r.Data[0:2]) == 0 {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	return nil
+}
+
+// Mkdir Creates the named directory
+func (f *FileMemory) Mkdir(name string) error {
+	return f.directoryCreateDelete("mkdir", name, directoryCreate)
+}
+
+// RemoveDir Deletes the named directory, which must be empty
+func (f *FileMemory) RemoveDir(name string) error {
+	return f.directoryCreateDelete("removedir", name, directoryDelete)
+}
+
+// Rename Renames a file or directory, which must stay in the same directory
+func (f *FileMemory) Rename(oldName string, newName string) error {
+	dir, oldFile, e := splitFilePath("rename", oldName)
+	if e != nil {
+		return e
+	}
+	newDir, newFile, e := splitFilePath("rename", newName)
+	if e != nil {
+		return e
+	}
+	if newDir != dir {
+		return &fs.PathError{Op: "rename", Path: newName, Err: errors.New("cannot move to another directory")}
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeFileNameChange,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, oldFile...)
+	command.Data = append(command.Data, newFile...)
+	_, e = f.client.execute(command)
+	return fileError("rename", oldName, e)
+}
+
+// Copy Copies the named file to dstName in dst, which may be the file memory of another disk of the same CPU unit
+func (f *FileMemory) Copy(name string, dst *FileMemory, dstName string) error {
+	dir, file, e := splitFilePath("copy", name)
+	if e != nil {
+		return e
+	}
+	dstDir, dstFile, e := splitFilePath("copy", dstName)
+	if e != nil {
+		return e
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeFileCopy,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, file...)
+	command.Data = append(command.Data, byte(dst.disk>>8), byte(dst.disk))
+	command.Data = append(command.Data, encodeFileDirectory(dstDir)...)
+	command.Data = append(command.Data, dstFile...)
+	_, e = f.client.execute(command)
+	return fileError("copy", name, e)
+}
+
+// Format Formats the file memory, deleting every file and directory
+func (f *FileMemory) Format() error {
+	command := &Payload{
+		CommandCode: CommandCodeFileMemoryFormat,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+	_, e := f.client.execute(command)
+	return e
+}
+
+// DiskData Reads the volume label and capacity of the file memory
+func (f *FileMemory) DiskData() (*DiskData, error) {
+	d, _, e := f.readDirectory(plcDirectory("."))
+	return d, e
+}
+
+func (f *FileMemory) stat(op string, name string) (*fileMemoryInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &fileMemoryInfo{name: ".", dir: true}, nil
+	}
+
+	_, infos, e := f.readDirectory(plcDirectory(path.Dir(name)))
+	if e != nil {
+		return nil, fileError(op, name, e)
+	}
+	base := path.Base(name)
+	for _, info := range infos {
+		if strings.EqualFold(info.name, base) {
+			return info, nil
+		}
+	}
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+func (f *FileMemory) readDirectory(dir string) (*DiskData, []*fileMemoryInfo, error) {
+	var disk *DiskData
+	infos := make([]*fileMemoryInfo, 0)
+	for position := 0; ; {
+		command := &Payload{
+			CommandCode: CommandCodeFileNameRead,
+			Data:        make([]byte, 6),
+		}
+		binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+		binary.BigEndian.PutUint16(command.Data[2:4], uint16(position))
+		binary.BigEndian.PutUint16(command.Data[4:6], fileNameReadMax)
+		command.Data = append(command.Data, encodeFileDirectory(dir)...)
+		r, e := f.client.execute(command)
+		if e != nil {
+			return nil, nil, e
+		}
+
+		if len(r.Data) < diskDataLength+2 {
+			return nil, nil, ErrResponseTooShort
+		}
+		if disk == nil {
+			disk = &DiskData{
+				VolumeLabel: decodeASCII(r.Data[0:12]),
+				Time:        decodeFileTime(binary.BigEndian.Uint32(r.Data[12:16]), f.client.Location()),
+				Capacity:    int64(binary.BigEndian.Uint32(r.Data[16:20])),
+				Free:        int64(binary.BigEndian.Uint32(r.Data[20:24])),
+			}
+		}
+		count := binary.BigEndian.Uint16(r.Data[diskDataLength : diskDataLength+2])
+		last := count&fileLastFlag != 0
+		count &^= fileLastFlag
+		entries := r.Data[diskDataLength+2:]
+		if len(entries) < int(count)*fileDataLength {
+			return nil, nil, ErrResponseTooShort
+		}
+		for i := 0; i < int(count); i++ {
+			entry := entries[i*fileDataLength : (i+1)*fileDataLength]
+			infos = append(infos, decodeFileData(entry, f.client.Location()))
+		}
+
+		position += int(count)
+		if last || count == 0 {
+			break
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].name < infos[j].name })
+	return disk, infos, nil
+}
+
+func (f *FileMemory) readFileChunk(dir string, file []byte, position int64, length int) (int64, []byte, error) {
+	command := &Payload{
+		CommandCode: CommandCodeSingleFileRead,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+	command.Data = append(command.Data, file...)
+	command.Data = append(command.Data, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(command.Data[2+fileNameLength:], uint32(position))
+	binary.BigEndian.PutUint16(command.Data[6+fileNameLength:], uint16(length))
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	r, e := f.client.execute(command)
+	if e != nil {
+		return 0, nil, e
+	}
+
+	if len(r.Data) < 10 {
+		return 0, nil, ErrResponseTooShort
+	}
+	size := int64(binary.BigEndian.Uint32(r.Data[0:4]))
+	n := int(binary.BigEndian.Uint16(r.Data[8:10]) &^ fileLastFlag)
+	if len(r.Data) < 10+n {
+		return 0, nil, ErrResponseTooShort
+	}
+	return size, r.Data[10 : 10+n], nil
+}
+
+func (f *FileMemory) writeFileChunk(parameter uint16, dir string, file []byte, position int64, data []byte) error {
+	command := &Payload{
+		CommandCode: CommandCodeSingleFileWrite,
+		Data:        make([]byte, 4, 4+fileNameLength+8+len(dir)+len(data)),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+	binary.BigEndian.PutUint16(command.Data[2:4], parameter)
+	command.Data = append(command.Data, file...)
+	command.Data = append(command.Data, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(command.Data[4+fileNameLength:], uint32(position))
+	binary.BigEndian.PutUint16(command.Data[8+fileNameLength:], uint16(len(data)))
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, data...)
+	_, e := f.client.execute(command)
+	return e
+}
+
+func (f *FileMemory) directoryCreateDelete(op string, name string, parameter uint16) error {
+	dir, file, e := splitFilePath(op, name)
+	if e != nil {
+		return e
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeDirectoryCreateDelete,
+		Data:        make([]byte, 4),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], f.disk)
+	binary.BigEndian.PutUint16(command.Data[2:4], parameter)
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, file...)
+	_, e = f.client.execute(command)
+	return fileError(op, name, e)
+}
+
+// fileMemoryFile A file of the file memory opened for reading
+type fileMemoryFile struct {
+	fm       *FileMemory
+	name     string
+	info     *fileMemoryInfo
+	position int64
+}
+
+func (f *fileMemoryFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *fileMemoryFile) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if f.position >= f.info.size {
+		return 0, io.EOF
+	}
+	n := len(p)
+	if n > fileTransferMax {
+		n = fileTransferMax
+	}
+	if remaining := f.info.size - f.position; int64(n) > remaining {
+		n = int(remaining)
+	}
+
+	dir, file, e := splitFilePath("read", f.name)
+	if e != nil {
+		return 0, e
+	}
+	_, chunk, e := f.fm.readFileChunk(dir, file, f.position, n)
+	if e != nil {
+		return 0, fileError("read", f.name, e)
+	}
+	if len(chunk) == 0 {
+		return 0, io.EOF
+	}
+	n = copy(p, chunk)
+	f.position += int64(n)
+	return n, nil
+}
+
+func (f *fileMemoryFile) Close() error {
+	return nil
+}
+
+// fileMemoryDirectory A directory of the file memory opened for reading
+type fileMemoryDirectory struct {
+	fm      *FileMemory
+	name    string
+	info    *fileMemoryInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *fileMemoryDirectory) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *fileMemoryDirectory) Read(p []byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *fileMemoryDirectory) Close() error {
+	return nil
+}
+
+func (d *fileMemoryDirectory) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, e := d.fm.ReadDir(d.name)
+		if e != nil {
+			return nil, e
+		}
+		d.entries = entries
+		d.read = true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+// fileMemoryInfo Information on a file or directory of the file memory
+type fileMemoryInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *fileMemoryInfo) Name() string {
+	return i.name
+}
+
+func (i *fileMemoryInfo) Size() int64 {
+	return i.size
+}
+
+func (i *fileMemoryInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (i *fileMemoryInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+func (i *fileMemoryInfo) IsDir() bool {
+	return i.dir
+}
+
+func (i *fileMemoryInfo) Sys() interface{} {
+	return nil
+}
+
+func (i *fileMemoryInfo) Type() fs.FileMode {
+	return i.Mode().Type()
+}
+
+func (i *fileMemoryInfo) Info() (fs.FileInfo, error) {
+	return i, nil
+}
+
+// decodeFileData Decodes a directory entry, holding the name and extension in the MS-DOS layout of 8 and 3
+// characters followed by the attribute byte, then the date and time and the size
+func decodeFileData(data []byte, location *time.Location) *fileMemoryInfo {
+	name := decodeASCII(data[0:8])
+	if ext := decodeASCII(data[8:11]); ext != "" {
+		name += "." + ext
+	}
+	return &fileMemoryInfo{
+		name:    name,
+		modTime: decodeFileTime(binary.BigEndian.Uint32(data[12:16]), location),
+		size:    int64(binary.BigEndian.Uint32(data[16:20])),
+		dir:     data[11]&fileAttributeDirectory != 0,
+	}
+}
+
+func encodeFileData(info *fileMemoryInfo) []byte {
+	data := make([]byte, fileDataLength)
+	base, ext := info.name, ""
+	if n := strings.LastIndexByte(info.name, '.'); n >= 0 {
+		base, ext = info.name[:n], info.name[n+1:]
+	}
+	copy(data[0:8], encodeASCII(strings.ToUpper(base), 8))
+	copy(data[8:11], encodeASCII(strings.ToUpper(ext), 3))
+	if info.dir {
+		data[11] = fileAttributeDirectory
+	}
+	binary.BigEndian.PutUint32(data[12:16], encodeFileTime(info.modTime))
+	binary.BigEndian.PutUint32(data[16:20], uint32(info.size))
+	return data
+}
+
+// splitFilePath Splits an io/fs path into the absolute directory path and the encoded name used by the CPU unit
+func splitFilePath(op string, name string) (string, []byte, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	file, e := encodeFileName(path.Base(name))
+	if e != nil {
+		return "", nil, &fs.PathError{Op: op, Path: name, Err: e}
+	}
+	return plcDirectory(path.Dir(name)), file, nil
+}
+
+// plcDirectory Converts an io/fs directory path to the absolute directory path used by the CPU unit
+func plcDirectory(name string) string {
+	if name == "." {
+		return `\`
+	}
+	return `\` + strings.ToUpper(strings.ReplaceAll(name, "/", `\`))
+}
+
+func encodeFileDirectory(dir string) []byte {
+	data := make([]byte, 2, 2+len(dir))
+	binary.BigEndian.PutUint16(data, uint16(len(dir)))
+	return append(data, dir...)
+}
+
+// encodeFileName Encodes a file name in the 8.3 format, padded with spaces
+func encodeFileName(name string) ([]byte, error) {
+	base, ext := name, ""
+	if n := strings.LastIndexByte(name, '.'); n >= 0 {
+		base, ext = name[:n], name[n+1:]
+	}
+	if len(base) == 0 || len(base) > 8 || len(ext) > 3 || strings.ContainsAny(name, ` \/:*?"<>|`) {
+		return nil, fs.ErrInvalid
+	}
+
+	if ext != "" {
+		base += "." + ext
+	}
+	return encodeASCII(strings.ToUpper(base), fileNameLength), nil
+}
+
+func decodeFileName(data []byte) string {
+	name := decodeASCII(data)
+	if n := strings.LastIndexByte(name, '.'); n >= 0 {
+		base, ext := strings.TrimSpace(name[:n]), strings.TrimSpace(name[n+1:])
+		if ext == "" {
+			return base
+		}
+		return base + "." + ext
+	}
+	return strings.TrimSpace(name)
+}
+
+// decodeFileTime Decodes an MS-DOS file date and time
+func decodeFileTime(t uint32, location *time.Location) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Date(
+		int(t>>25)+1980, time.Month(t>>21&0x0f), int(t>>16&0x1f),
+		int(t>>11&0x1f), int(t>>5&0x3f), int(t&0x1f)*2,
+		0, // nanosecond
+		location,
+	)
+}
+
+// encodeFileTime Encodes an MS-DOS file date and time
+func encodeFileTime(t time.Time) uint32 {
+	if t.Year() < 1980 {
+		return 0
+	}
+	return uint32(t.Year()-1980)<<25 | uint32(t.Month())<<21 | uint32(t.Day())<<16 |
+		uint32(t.Hour())<<11 | uint32(t.Minute())<<5 | uint32(t.Second()/2)
+}
+
+// fileError Wraps an error of a file command as an *fs.PathError, translating end codes to the errors of io/fs
+func fileError(op string, name string, e error) error {
+	if e == nil {
+		return nil
+	}
+	var ec *EndCodeError
+	if errors.As(e, &ec) {
+		switch ec.EndCode {
+		case EndCodeReadNotPossibleFileMissing, EndCodeWriteNotPossibleFileMissing:
+			e = fs.ErrNotExist
+		case EndCodeWriteNotPossibleFileNameAlreadyExists:
+			e = fs.ErrExist
+		case EndCodeReadNotPossibleProtected, EndCodeWriteNotPossibleProtected:
+			e = fs.ErrPermission
+		}
+	}
+	return &fs.PathError{Op: op, Path: name, Err: e}
+}
@@ -0,0 +1,127 @@
+package fins
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileData(t *testing.T) {
+	modTime := time.Date(2021, time.July, 4, 10, 30, 58, 0, time.UTC)
+	info := &fileMemoryInfo{name: "data.csv", size: 1234, modTime: modTime}
+	data := encodeFileData(info)
+	assert.Equal(t, "DATA    CSV\x00", string(data[0:12]))
+	assert.Equal(t, uint32(41<<25|7<<21|4<<16|10<<11|30<<5|29), encodeFileTime(modTime))
+	assert.Equal(t, &fileMemoryInfo{name: "DATA.CSV", size: 1234, modTime: modTime}, decodeFileData(data, time.UTC))
+
+	dir := decodeFileData(encodeFileData(&fileMemoryInfo{name: "LOGS", dir: true}), time.UTC)
+	assert.Equal(t, &fileMemoryInfo{name: "LOGS", dir: true}, dir)
+	assert.Equal(t, fs.ModeDir|0555, dir.Mode())
+
+	name, e := encodeFileName("abc.de")
+	require.NoError(t, e)
+	assert.Equal(t, "ABC.DE      ", string(name))
+	assert.Equal(t, "ABC.DE", decodeFileName(name))
+	for _, invalid := range []string{"", ".txt", "toolongname.txt", "name.text", "a b.txt", "a*.txt"} {
+		_, e = encodeFileName(invalid)
+		assert.Equal(t, fs.ErrInvalid, e, invalid)
+	}
+}
+
+func TestFileMemory(t *testing.T) {
+	_, c := newSimulator(t)
+	fm := c.FileMemory(DiskMemoryCard)
+
+	// More data than fits in one command
+	data := bytes.Repeat([]byte("0123456789"), 250)
+	require.NoError(t, fm.Mkdir("logs"))
+	require.NoError(t, fm.WriteFile("logs/day1.csv", data))
+	require.NoError(t, fm.WriteFile("readme.txt", []byte("hello")))
+	require.NoError(t, fstest.TestFS(fm, "README.TXT", "LOGS/DAY1.CSV"))
+
+	read, e := fm.ReadFile("LOGS/DAY1.CSV")
+	require.NoError(t, e)
+	assert.Equal(t, data, read)
+	f, e := fm.Open("logs/day1.csv")
+	require.NoError(t, e)
+	read, e = io.ReadAll(f)
+	require.NoError(t, e)
+	assert.Equal(t, data, read)
+	require.NoError(t, f.Close())
+
+	info, e := fm.Stat("logs/day1.csv")
+	require.NoError(t, e)
+	assert.Equal(t, int64(len(data)), info.Size())
+	assert.False(t, info.IsDir())
+	disk, e := fm.DiskData()
+	require.NoError(t, e)
+	assert.Equal(t, &DiskData{VolumeLabel: "SIMULATOR", Capacity: serverFileMemoryCapacity,
+		Free: serverFileMemoryCapacity - int64(len(data)) - 5}, disk)
+
+	require.NoError(t, fm.Rename("readme.txt", "notes.txt"))
+	require.NoError(t, fm.Copy("notes.txt", fm, "logs/notes.txt"))
+	read, e = fm.ReadFile("logs/notes.txt")
+	require.NoError(t, e)
+	assert.Equal(t, []byte("hello"), read)
+	require.NoError(t, fm.Remove("notes.txt"))
+
+	entries, e := fs.ReadDir(fm, ".")
+	require.NoError(t, e)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "LOGS", entries[0].Name())
+	assert.True(t, entries[0].IsDir())
+
+	_, e = fm.ReadFile("notes.txt")
+	assert.True(t, errors.Is(e, fs.ErrNotExist), "error %v", e)
+	assert.True(t, errors.Is(fm.Remove("notes.txt"), fs.ErrNotExist))
+	assert.True(t, errors.Is(fm.Mkdir("logs"), fs.ErrExist))
+	assert.True(t, errors.Is(fm.Copy("logs/notes.txt", fm, "logs/day1.csv"), fs.ErrExist))
+	assert.True(t, errors.Is(fm.WriteFile("missing/a.txt", nil), fs.ErrNotExist))
+	assert.True(t, errors.Is(fm.WriteFile("toolongname.txt", nil), fs.ErrInvalid))
+	assert.Error(t, fm.Rename("logs/notes.txt", "notes.txt"), "moving to another directory")
+	assert.Error(t, fm.RemoveDir("logs"), "removing a directory that is not empty")
+
+	require.NoError(t, fm.Format())
+	entries, e = fm.ReadDir(".")
+	require.NoError(t, e)
+	assert.Empty(t, entries)
+
+	var ec *EndCodeError
+	assert.True(t, errors.As(c.FileMemory(DiskEMFileMemory).Mkdir("logs"), &ec))
+}
+
+func TestFileMemoryReadDir(t *testing.T) {
+	_, c := newSimulator(t)
+	fm := c.FileMemory(DiskMemoryCard)
+
+	// More entries than are read in one command
+	for i := 0; i < fileNameReadMax+5; i++ {
+		require.NoError(t, fm.WriteFile(fmt.Sprintf("F%02d.DAT", i), []byte{byte(i)}))
+	}
+	entries, e := fm.ReadDir(".")
+	require.NoError(t, e)
+	require.Len(t, entries, fileNameReadMax+5)
+	for i, entry := range entries {
+		assert.Equal(t, fmt.Sprintf("F%02d.DAT", i), entry.Name())
+	}
+
+	d, e := fm.Open(".")
+	require.NoError(t, e)
+	dir := d.(fs.ReadDirFile)
+	entries, e = dir.ReadDir(40)
+	require.NoError(t, e)
+	assert.Len(t, entries, 40)
+	entries, e = dir.ReadDir(40)
+	require.NoError(t, e)
+	assert.Len(t, entries, 5)
+	_, e = dir.ReadDir(40)
+	assert.Equal(t, io.EOF, e)
+}
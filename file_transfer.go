@@ -0,0 +1,160 @@
+package fins
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+)
+
+// FileTransfer Direction of a transfer between a memory area and a file
+type FileTransfer uint16
+
+const (
+	// FileTransferToFile Copies the memory area to the file, creating or replacing it
+	FileTransferToFile FileTransfer = 0x0000
+
+	// FileTransferFromFile Copies the file to the memory area
+	FileTransferFromFile FileTransfer = 0x0001
+
+	// FileTransferCompare Compares the memory area with the file
+	FileTransferCompare FileTransfer = 0x0002
+)
+
+const (
+	// ParameterAreaPLCSetup Parameter area code: PLC setup
+	ParameterAreaPLCSetup uint16 = 0x8010
+
+	// ParameterAreaIOTable Parameter area code: registered IO table
+	ParameterAreaIOTable uint16 = 0x8012
+
+	// ParameterAreaRoutingTable Parameter area code: routing tables
+	ParameterAreaRoutingTable uint16 = 0x8013
+
+	// ParameterAreaCPUBusUnitSetup Parameter area code: CPU bus unit setup
+	ParameterAreaCPUBusUnitSetup uint16 = 0x8002
+)
+
+// ErrFileTransferMismatch Error when a compared memory area differs from the file
+var ErrFileTransferMismatch = errors.New("memory area differs from file")
+
+// TransferMemoryArea Transfers count items of the memory area starting at address between the CPU unit and the named
+// file, without the data passing through the network, returning the number of items transferred. A compare that
+// finds a difference returns ErrFileTransferMismatch
+func (f *FileMemory) TransferMemoryArea(direction FileTransfer, name string, memoryArea byte, address uint16,
+	count uint16) (uint16, error) {
+	dir, file, e := splitFilePath("transfer", name)
+	if e != nil {
+		return 0, e
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeMemoryAreaFileTransfer,
+		Data:        make([]byte, 10),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], uint16(direction))
+	copy(command.Data[2:6], encodeIOAddress(IOAddress{MemoryArea: memoryArea, Address: address}))
+	binary.BigEndian.PutUint16(command.Data[6:8], count)
+	binary.BigEndian.PutUint16(command.Data[8:10], f.disk)
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, file...)
+	r, e := f.client.execute(command)
+	if e != nil {
+		return 0, fileTransferError(name, e)
+	}
+
+	if len(r.Data) < 2 {
+		return 0, ErrResponseTooShort
+	}
+	return binary.BigEndian.Uint16(r.Data[0:2]), nil
+}
+
+// SaveMemoryArea Copies count items of the memory area starting at address to the named file
+func (f *FileMemory) SaveMemoryArea(name string, memoryArea byte, address uint16, count uint16) error {
+	_, e := f.TransferMemoryArea(FileTransferToFile, name, memoryArea, address, count)
+	return e
+}
+
+// LoadMemoryArea Copies count items of the named file to the memory area starting at address
+func (f *FileMemory) LoadMemoryArea(name string, memoryArea byte, address uint16, count uint16) error {
+	_, e := f.TransferMemoryArea(FileTransferFromFile, name, memoryArea, address, count)
+	return e
+}
+
+// CompareMemoryArea Reports whether count items of the memory area starting at address equal the named file
+func (f *FileMemory) CompareMemoryArea(name string, memoryArea byte, address uint16, count uint16) (bool, error) {
+	_, e := f.TransferMemoryArea(FileTransferCompare, name, memoryArea, address, count)
+	if errors.Is(e, ErrFileTransferMismatch) {
+		return false, nil
+	}
+	return e == nil, e
+}
+
+// TransferParameterArea Transfers count words of the parameter area starting at word first between the CPU unit and
+// the named file, returning the number of words transferred. Transfers from a file require PROGRAM mode
+func (f *FileMemory) TransferParameterArea(direction FileTransfer, name string, parameterArea uint16, first uint16,
+	count uint16) (uint16, error) {
+	dir, file, e := splitFilePath("transfer", name)
+	if e != nil {
+		return 0, e
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeParameterAreaFileTransfer,
+		Data:        make([]byte, 10),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], uint16(direction))
+	binary.BigEndian.PutUint16(command.Data[2:4], parameterArea)
+	binary.BigEndian.PutUint16(command.Data[4:6], first)
+	binary.BigEndian.PutUint16(command.Data[6:8], count)
+	binary.BigEndian.PutUint16(command.Data[8:10], f.disk)
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, file...)
+	r, e := f.client.execute(command)
+	if e != nil {
+		return 0, fileTransferError(name, e)
+	}
+
+	if len(r.Data) < 2 {
+		return 0, ErrResponseTooShort
+	}
+	return binary.BigEndian.Uint16(r.Data[0:2]), nil
+}
+
+// TransferProgramArea Transfers count words of the user program starting at word first between the CPU unit and the
+// named file, returning the number of words transferred. Transfers from a file require PROGRAM mode
+func (f *FileMemory) TransferProgramArea(direction FileTransfer, name string, first uint32, count uint32) (uint32,
+	error) {
+	dir, file, e := splitFilePath("transfer", name)
+	if e != nil {
+		return 0, e
+	}
+
+	command := &Payload{
+		CommandCode: CommandCodeProgramAreaFileTransfer,
+		Data:        make([]byte, 14),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], uint16(direction))
+	binary.BigEndian.PutUint16(command.Data[2:4], ProgramNumberCurrent)
+	binary.BigEndian.PutUint32(command.Data[4:8], first)
+	binary.BigEndian.PutUint32(command.Data[8:12], count)
+	binary.BigEndian.PutUint16(command.Data[12:14], f.disk)
+	command.Data = append(command.Data, encodeFileDirectory(dir)...)
+	command.Data = append(command.Data, file...)
+	r, e := f.client.execute(command)
+	if e != nil {
+		return 0, fileTransferError(name, e)
+	}
+
+	if len(r.Data) < 4 {
+		return 0, ErrResponseTooShort
+	}
+	return binary.BigEndian.Uint32(r.Data[0:4]), nil
+}
+
+func fileTransferError(name string, e error) error {
+	var ec *EndCodeError
+	if errors.As(e, &ec) && ec.EndCode == EndCodeReadNotPossibleDataMismatch {
+		return &fs.PathError{Op: "transfer", Path: name, Err: ErrFileTransferMismatch}
+	}
+	return fileError("transfer", name, e)
+}
@@ -0,0 +1,72 @@
+package fins
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferMemoryArea(t *testing.T) {
+	_, c := newSimulator(t)
+	fm := c.FileMemory(DiskMemoryCard)
+
+	require.NoError(t, c.WriteWords(MemoryAreaDMWord, 100, []uint16{0x0102, 0x0304, 0x0506}))
+	require.NoError(t, fm.SaveMemoryArea("dm100.ion", MemoryAreaDMWord, 100, 3))
+	data, e := fm.ReadFile("DM100.ION")
+	require.NoError(t, e)
+	assert.Equal(t, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, data)
+
+	same, e := fm.CompareMemoryArea("dm100.ion", MemoryAreaDMWord, 100, 3)
+	require.NoError(t, e)
+	assert.True(t, same)
+	require.NoError(t, c.WriteWords(MemoryAreaDMWord, 101, []uint16{0}))
+	same, e = fm.CompareMemoryArea("dm100.ion", MemoryAreaDMWord, 100, 3)
+	require.NoError(t, e)
+	assert.False(t, same)
+	_, e = fm.TransferMemoryArea(FileTransferCompare, "dm100.ion", MemoryAreaDMWord, 100, 3)
+	assert.True(t, errors.Is(e, ErrFileTransferMismatch), "error %v", e)
+
+	// A file shorter than the memory area transfers only its own words
+	require.NoError(t, fm.LoadMemoryArea("dm100.ion", MemoryAreaHRWord, 0, 3))
+	n, e := fm.TransferMemoryArea(FileTransferFromFile, "dm100.ion", MemoryAreaWRWord, 0, 10)
+	require.NoError(t, e)
+	assert.Equal(t, uint16(3), n)
+	words, e := c.ReadWords(MemoryAreaWRWord, 0, 4)
+	require.NoError(t, e)
+	assert.Equal(t, []uint16{0x0102, 0x0304, 0x0506, 0}, words)
+
+	_, e = fm.TransferMemoryArea(FileTransferFromFile, "missing.ion", MemoryAreaDMWord, 0, 1)
+	assert.True(t, errors.Is(e, fs.ErrNotExist), "error %v", e)
+	assert.True(t, errors.Is(fm.SaveMemoryArea("a/b/c.ion", MemoryAreaDMWord, 0, 1), fs.ErrNotExist))
+}
+
+func TestTransferParameterProgramArea(t *testing.T) {
+	p, c := newTestClient(func(command *Payload) *Response {
+		return &Response{CommandCode: command.CommandCode, Data: []byte{0x00, 0x00, 0x01, 0x00}}
+	})
+	fm := c.FileMemory(DiskMemoryCard)
+
+	n, e := fm.TransferParameterArea(FileTransferToFile, "setup.std", ParameterAreaPLCSetup, 0, 0x100)
+	require.NoError(t, e)
+	assert.Equal(t, uint16(0), n)
+	assert.Equal(t, CommandCodeParameterAreaFileTransfer, p.command.CommandCode)
+	assert.Equal(t, "0000"+"8010"+"0000"+"0100"+"8000"+"0001"+hex.EncodeToString([]byte(`\SETUP.STD   `)),
+		hex.EncodeToString(p.command.Data))
+
+	words, e := fm.TransferProgramArea(FileTransferFromFile, "prog/main.obj", 0, 0x100)
+	require.NoError(t, e)
+	assert.Equal(t, uint32(0x100), words)
+	assert.Equal(t, CommandCodeProgramAreaFileTransfer, p.command.CommandCode)
+	assert.Equal(t, "0001"+"ffff"+"00000000"+"00000100"+"8000"+"0005"+
+		hex.EncodeToString([]byte(`\PROGMAIN.OBJ    `)), hex.EncodeToString(p.command.Data))
+
+	p.respond = func(command *Payload) *Response {
+		return &Response{CommandCode: command.CommandCode, EndCode: EndCodeReadNotPossibleDataMismatch}
+	}
+	_, e = fm.TransferProgramArea(FileTransferCompare, "main.obj", 0, 0x100)
+	assert.True(t, errors.Is(e, ErrFileTransferMismatch), "error %v", e)
+}
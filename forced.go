@@ -0,0 +1,264 @@
+package fins
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+)
+
+// ForceAction Action of a forced set/reset on a bit
+type ForceAction uint16
+
+const (
+	// ForceReset Forces the bit OFF
+	ForceReset ForceAction = 0x0000
+
+	// ForceSet Forces the bit ON
+	ForceSet ForceAction = 0x0001
+
+	// ForceReleaseOff Releases the forced status, leaving the bit OFF
+	ForceReleaseOff ForceAction = 0x8000
+
+	// ForceReleaseOn Releases the forced status, leaving the bit ON
+	ForceReleaseOn ForceAction = 0x8001
+
+	// ForceRelease Releases the forced status, leaving the bit in its current state
+	ForceRelease ForceAction = 0xffff
+)
+
+// forcedBitLength Length of each bit specification of a forced set/reset command
+const forcedBitLength = 6
+
+// forcedBitMemoryAreas Memory area reading the forced status of each bit memory area
+var forcedBitMemoryAreas = map[byte]byte{
+	MemoryAreaCIOBit: MemoryAreaCIOBitForced,
+	MemoryAreaWRBit:  MemoryAreaWRBitForced,
+	MemoryAreaHRBit:  MemoryAreaHRBitForced,
+}
+
+// forcedWordMemoryAreas Memory area reading the forced status of each word memory area
+var forcedWordMemoryAreas = map[byte]byte{
+	MemoryAreaCIOWord: MemoryAreaCIOWordForced,
+	MemoryAreaWRWord:  MemoryAreaWRWordForced,
+	MemoryAreaHRWord:  MemoryAreaHRWordForced,
+}
+
+// ForcedBit A bit and the forced set/reset action to apply to it
+type ForcedBit struct {
+	Action     ForceAction
+	MemoryArea byte
+	Address    uint16
+	BitOffset  byte
+}
+
+// BitStatus The state of a bit and whether it is forced
+type BitStatus struct {
+	Value  bool
+	Forced bool
+}
+
+// WordStatus The contents of a word and which of its bits are forced
+type WordStatus struct {
+	Value  uint16
+	Forced uint16
+}
+
+// ForceBits Applies forced set/reset actions to the given bits of bit memory areas in one command. The CPU unit must
+// be in PROGRAM or MONITOR mode
+func (c *Client) ForceBits(bits []ForcedBit) error {
+	command := &Payload{
+		CommandCode: CommandCodeForcedSetReset,
+		Data:        make([]byte, 2, 2+len(bits)*forcedBitLength),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], uint16(len(bits)))
+	for _, bit := range bits {
+		command.Data = append(command.Data, byte(bit.Action>>8), byte(bit.Action))
+		command.Data = append(command.Data, encodeIOAddress(IOAddress{
+			MemoryArea: bit.MemoryArea,
+			Address:    bit.Address,
+			BitOffset:  bit.BitOffset,
+		})...)
+	}
+	_, e := c.execute(command)
+	return e
+}
+
+// ForceSetBit Forces a bit ON
+func (c *Client) ForceSetBit(memoryArea byte, address uint16, bitOffset byte) error {
+	return c.ForceBits([]ForcedBit{{ForceSet, memoryArea, address, bitOffset}})
+}
+
+// ForceResetBit Forces a bit OFF
+func (c *Client) ForceResetBit(memoryArea byte, address uint16, bitOffset byte) error {
+	return c.ForceBits([]ForcedBit{{ForceReset, memoryArea, address, bitOffset}})
+}
+
+// ReleaseForcedBit Releases the forced status of a bit, leaving it in its current state
+func (c *Client) ReleaseForcedBit(memoryArea byte, address uint16, bitOffset byte) error {
+	return c.ForceBits([]ForcedBit{{ForceRelease, memoryArea, address, bitOffset}})
+}
+
+// CancelAllForced Releases the forced status of every forced bit of the CPU unit
+func (c *Client) CancelAllForced() error {
+	command := &Payload{
+		CommandCode: CommandCodeForcedSetResetCancel,
+		Data:        []byte{},
+	}
+	_, e := c.execute(command)
+	return e
+}
+
+// ReadForcedBits Reads the state and forced status of bits of the CIO, work or holding area, given by its bit
+// memory area
+func (c *Client) ReadForcedBits(memoryArea byte, address uint16, bitOffset byte, readCount uint16) ([]BitStatus,
+	error) {
+	forcedArea, ok := forcedBitMemoryAreas[memoryArea]
+	if !ok {
+		return nil, ErrIncompatibleMemoryArea
+	}
+	r, e := c.execute(readCommand(IOAddress{
+		MemoryArea: forcedArea,
+		Address:    address,
+		BitOffset:  bitOffset,
+	}, readCount))
+	if e != nil {
+		return nil, e
+	}
+	if len(r.Data) < int(readCount) {
+		return nil, ErrResponseTooShort
+	}
+
+	result := make([]BitStatus, readCount)
+	for i := range result {
+		result[i] = BitStatus{
+			Value:  r.Data[i]&0x01 != 0,
+			Forced: r.Data[i]&0x02 != 0,
+		}
+	}
+	return result, nil
+}
+
+// ReadForcedWords Reads the contents and forced bits of words of the CIO, work or holding area, given by its word
+// memory area
+func (c *Client) ReadForcedWords(memoryArea byte, address uint16, readCount uint16) ([]WordStatus, error) {
+	forcedArea, ok := forcedWordMemoryAreas[memoryArea]
+	if !ok {
+		return nil, ErrIncompatibleMemoryArea
+	}
+	r, e := c.execute(readCommand(IOAddress{
+		MemoryArea: forcedArea,
+		Address:    address,
+	}, readCount))
+	if e != nil {
+		return nil, e
+	}
+	if len(r.Data) < int(readCount)*4 {
+		return nil, ErrResponseTooShort
+	}
+
+	result := make([]WordStatus, readCount)
+	for i := range result {
+		result[i] = WordStatus{
+			Value:  binary.BigEndian.Uint16(r.Data[i*4 : i*4+2]),
+			Forced: binary.BigEndian.Uint16(r.Data[i*4+2 : i*4+4]),
+		}
+	}
+	return result, nil
+}
+
+// ForceSession Forces bits through a client and keeps track of them, so that every force made during the session
+// can be released when it ends
+type ForceSession struct {
+	client *Client
+	forced map[IOAddress]ForceAction
+
+	sync.Mutex
+}
+
+// NewForceSession Creates a force session
+func NewForceSession(client *Client) *ForceSession {
+	s := new(ForceSession)
+	s.client = client
+	s.forced = make(map[IOAddress]ForceAction)
+	return s
+}
+
+// Force Applies forced set/reset actions to the given bits, tracking forced bits until they are released
+func (s *ForceSession) Force(bits []ForcedBit) error {
+	s.Lock()
+	defer s.Unlock()
+	if e := s.client.ForceBits(bits); e != nil {
+		return e
+	}
+	for _, bit := range bits {
+		ioAddr := IOAddress{MemoryArea: bit.MemoryArea, Address: bit.Address, BitOffset: bit.BitOffset}
+		if bit.Action == ForceSet || bit.Action == ForceReset {
+			s.forced[ioAddr] = bit.Action
+		} else {
+			delete(s.forced, ioAddr)
+		}
+	}
+	return nil
+}
+
+// Set Forces a bit ON
+func (s *ForceSession) Set(memoryArea byte, address uint16, bitOffset byte) error {
+	return s.Force([]ForcedBit{{ForceSet, memoryArea, address, bitOffset}})
+}
+
+// Reset Forces a bit OFF
+func (s *ForceSession) Reset(memoryArea byte, address uint16, bitOffset byte) error {
+	return s.Force([]ForcedBit{{ForceReset, memoryArea, address, bitOffset}})
+}
+
+// Release Releases the forced status of a bit, leaving it in its current state
+func (s *ForceSession) Release(memoryArea byte, address uint16, bitOffset byte) error {
+	return s.Force([]ForcedBit{{ForceRelease, memoryArea, address, bitOffset}})
+}
+
+// Forced Returns the bits forced during the session and not yet released, with their forced action
+func (s *ForceSession) Forced() []ForcedBit {
+	s.Lock()
+	defer s.Unlock()
+	bits := make([]ForcedBit, 0, len(s.forced))
+	for ioAddr, action := range s.forced {
+		bits = append(bits, ForcedBit{action, ioAddr.MemoryArea, ioAddr.Address, ioAddr.BitOffset})
+	}
+	sort.Slice(bits, func(i, j int) bool {
+		a, b := bits[i], bits[j]
+		if a.MemoryArea != b.MemoryArea {
+			return a.MemoryArea < b.MemoryArea
+		}
+		if a.Address != b.Address {
+			return a.Address < b.Address
+		}
+		return a.BitOffset < b.BitOffset
+	})
+	return bits
+}
+
+// Close Releases every bit forced during the session, leaving each in its current state. Bits forced by other tools
+// or operators are left forced. If releasing them fails, the bits stay tracked so that Close can be called again
+func (s *ForceSession) Close() error {
+	bits := s.Forced()
+	if len(bits) == 0 {
+		return nil
+	}
+	for i := range bits {
+		bits[i].Action = ForceRelease
+	}
+	return s.Force(bits)
+}
+
+// WithForceSession Runs fn with a new force session and releases every bit it forced afterwards, even if fn fails or
+// panics
+func (c *Client) WithForceSession(fn func(s *ForceSession) error) (err error) {
+	s := NewForceSession(c)
+	defer func() {
+		if e := s.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	return fn(s)
+}
@@ -0,0 +1,69 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForceBits(t *testing.T) {
+	_, c := newSimulator(t)
+
+	require.NoError(t, c.ForceSetBit(MemoryAreaCIOBit, 10, 3))
+	require.NoError(t, c.ForceResetBit(MemoryAreaCIOBit, 10, 4))
+	require.NoError(t, c.WriteWords(MemoryAreaCIOWord, 10, []uint16{0xfff0}))
+
+	bits, e := c.ReadForcedBits(MemoryAreaCIOBit, 10, 2, 4)
+	require.NoError(t, e)
+	assert.Equal(t, []BitStatus{{false, false}, {true, true}, {false, true}, {true, false}}, bits)
+	words, e := c.ReadForcedWords(MemoryAreaCIOWord, 10, 1)
+	require.NoError(t, e)
+	assert.Equal(t, []WordStatus{{Value: 0xffe8, Forced: 0x0018}}, words)
+
+	require.NoError(t, c.ReleaseForcedBit(MemoryAreaCIOBit, 10, 3))
+	require.NoError(t, c.ForceBits([]ForcedBit{{ForceReleaseOn, MemoryAreaCIOBit, 10, 4}}))
+	words, e = c.ReadForcedWords(MemoryAreaCIOWord, 10, 1)
+	require.NoError(t, e)
+	assert.Equal(t, []WordStatus{{Value: 0xfff8, Forced: 0}}, words)
+
+	require.NoError(t, c.ForceSetBit(MemoryAreaHRBit, 0, 0))
+	require.NoError(t, c.CancelAllForced())
+	words, e = c.ReadForcedWords(MemoryAreaHRWord, 0, 1)
+	require.NoError(t, e)
+	assert.Equal(t, uint16(0), words[0].Forced)
+
+	_, e = c.ReadForcedBits(MemoryAreaDMBit, 0, 0, 1)
+	assert.True(t, errors.Is(e, ErrIncompatibleMemoryArea))
+	var ec *EndCodeError
+	assert.True(t, errors.As(c.ForceSetBit(MemoryAreaDMBit, 0, 0), &ec))
+
+	require.NoError(t, c.Run())
+	assert.True(t, errors.As(c.ForceSetBit(MemoryAreaCIOBit, 0, 0), &ec), "forcing in RUN mode")
+}
+
+func TestForceSession(t *testing.T) {
+	_, c := newSimulator(t)
+
+	// A bit forced outside the session stays forced when the session ends
+	require.NoError(t, c.ForceSetBit(MemoryAreaWRBit, 0, 0))
+
+	failure := errors.New("failure")
+	e := c.WithForceSession(func(s *ForceSession) error {
+		require.NoError(t, s.Set(MemoryAreaWRBit, 1, 0))
+		require.NoError(t, s.Reset(MemoryAreaWRBit, 1, 1))
+		require.NoError(t, s.Set(MemoryAreaWRBit, 1, 2))
+		require.NoError(t, s.Release(MemoryAreaWRBit, 1, 2))
+		assert.Equal(t, []ForcedBit{
+			{ForceSet, MemoryAreaWRBit, 1, 0},
+			{ForceReset, MemoryAreaWRBit, 1, 1},
+		}, s.Forced())
+		return failure
+	})
+	assert.Equal(t, failure, e)
+
+	words, e := c.ReadForcedWords(MemoryAreaWRWord, 0, 2)
+	require.NoError(t, e)
+	assert.Equal(t, []WordStatus{{Value: 0x0001, Forced: 0x0001}, {Value: 0x0005, Forced: 0}}, words)
+}
@@ -2,4 +2,7 @@ module github.com/siyka-au/gofins
 
 go 1.16
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/stretchr/testify v1.7.0
+	golang.org/x/text v0.3.8
+)
@@ -52,6 +52,12 @@ func defaultHeader(dst Address, src Address, sid byte) *Header {
 	return h
 }
 
+func responseHeader(command *Header) *Header {
+	h := defaultHeader(command.src, command.dst, command.sid)
+	h.icf |= 1 << icfMessageTypeBit
+	return h
+}
+
 func newHeaderNoResponse(dst Address, src Address, sid byte) *Header {
 	h := defaultHeader(dst, src, sid)
 	h.SetToRequireNoResponse()
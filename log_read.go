@@ -0,0 +1,79 @@
+package fins
+
+import "encoding/binary"
+
+const (
+	logHeaderLength = 6
+
+	// logReadMax Largest number of records the CPU unit accepts in one Error Log Read or FINS Write Access Log Read
+	logReadMax = 20
+)
+
+// logRecords Records of fixed length read from a log of the CPU unit, with the header of the response
+type logRecords struct {
+	capacity int
+	stored   int
+	records  [][]byte
+}
+
+// readLogRecords Reads count records of recordLength bytes from the log read by commandCode, starting at record
+// number first
+func (c *Client) readLogRecords(commandCode uint16, recordLength int, first uint16, count uint16) (*logRecords,
+	error) {
+	command := &Payload{
+		CommandCode: commandCode,
+		Data:        make([]byte, 4),
+	}
+	binary.BigEndian.PutUint16(command.Data[0:2], first)
+	binary.BigEndian.PutUint16(command.Data[2:4], count)
+	r, e := c.execute(command)
+	if e != nil {
+		return nil, e
+	}
+
+	return decodeLogRecords(r.Data, recordLength)
+}
+
+// readAllLogRecords Reads every record of recordLength bytes stored in the log read by commandCode, reading at most
+// logReadMax records and at most the capacity of the log per command
+func (c *Client) readAllLogRecords(commandCode uint16, recordLength int) (*logRecords, error) {
+	log, e := c.readLogRecords(commandCode, recordLength, 0, logReadMax)
+	if e != nil {
+		return nil, e
+	}
+	count := logReadMax
+	if log.capacity > 0 && log.capacity < count {
+		count = log.capacity
+	}
+	for len(log.records) < log.stored {
+		next, e := c.readLogRecords(commandCode, recordLength, uint16(len(log.records)), uint16(count))
+		if e != nil {
+			return nil, e
+		}
+		if len(next.records) == 0 {
+			break
+		}
+		log.records = append(log.records, next.records...)
+	}
+	return log, nil
+}
+
+func decodeLogRecords(data []byte, recordLength int) (*logRecords, error) {
+	if len(data) < logHeaderLength {
+		return nil, ErrResponseTooShort
+	}
+	count := int(binary.BigEndian.Uint16(data[4:6]))
+	if len(data) < logHeaderLength+count*recordLength {
+		return nil, ErrResponseTooShort
+	}
+
+	log := &logRecords{
+		capacity: int(binary.BigEndian.Uint16(data[0:2])),
+		stored:   int(binary.BigEndian.Uint16(data[2:4])),
+		records:  make([][]byte, count),
+	}
+	for i := range log.records {
+		log.records[i] = data[logHeaderLength+i*recordLength : logHeaderLength+(i+1)*recordLength]
+	}
+	return log, nil
+}
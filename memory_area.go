@@ -1,5 +1,7 @@
 package fins
 
+import "errors"
+
 const (
 	// MemoryAreaCIOBit Memory area: CIO area; bit
 	MemoryAreaCIOBit byte = 0x30
@@ -10,7 +12,7 @@ const (
 	// MemoryAreaHRBit Memory area: holding area; bit
 	MemoryAreaHRBit byte = 0x32
 
-	// MemoryAreaARBit Memory area: axuillary area; bit
+	// MemoryAreaARBit Memory area: auxiliary area; bit
 	MemoryAreaARBit byte = 0x33
 
 	// MemoryAreaCIOWord Memory area: CIO area; word
@@ -22,13 +24,32 @@ const (
 	// MemoryAreaHRWord Memory area: holding area; word
 	MemoryAreaHRWord byte = 0xb2
 
-	// MemoryAreaARWord Memory area: auxillary area; word
+	// MemoryAreaARWord Memory area: auxiliary area; word
 	MemoryAreaARWord byte = 0xb3
 
-	// MemoryAreaTimerCounterCompletionFlag Memory area: counter completin flag
+	// MemoryAreaCIOBitForced Memory area: CIO area; bit with forced status
+	MemoryAreaCIOBitForced byte = 0x70
+
+	// MemoryAreaWRBitForced Memory area: work area; bit with forced status
+	MemoryAreaWRBitForced byte = 0x71
+
+	// MemoryAreaHRBitForced Memory area: holding area; bit with forced status
+	MemoryAreaHRBitForced byte = 0x72
+
+	// MemoryAreaCIOWordForced Memory area: CIO area; word with forced status
+	MemoryAreaCIOWordForced byte = 0xf0
+
+	// MemoryAreaWRWordForced Memory area: work area; word with forced status
+	MemoryAreaWRWordForced byte = 0xf1
+
+	// MemoryAreaHRWordForced Memory area: holding area; word with forced status
+	MemoryAreaHRWordForced byte = 0xf2
+
+	// MemoryAreaTimerCounterCompletionFlag Memory area: timer and counter completion flags; counters are addressed
+	// from CounterAddressOffset
 	MemoryAreaTimerCounterCompletionFlag byte = 0x09
 
-	// MemoryAreaTimerCounterPV Memory area: counter PV
+	// MemoryAreaTimerCounterPV Memory area: timer and counter PV; counters are addressed from CounterAddressOffset
 	MemoryAreaTimerCounterPV byte = 0x89
 
 	// MemoryAreaDMBit Memory area: data area; bit
@@ -37,18 +58,251 @@ const (
 	// MemoryAreaDMWord Memory area: data area; word
 	MemoryAreaDMWord byte = 0x82
 
+	// MemoryAreaEMCurrentBit Memory area: extended memory, current bank; bit
+	MemoryAreaEMCurrentBit byte = 0x0a
+
+	// MemoryAreaEMCurrentWord Memory area: extended memory, current bank; word
+	MemoryAreaEMCurrentWord byte = 0x98
+
+	// MemoryAreaEMCurrentBankNumber Memory area: extended memory current bank number
+	MemoryAreaEMCurrentBankNumber byte = 0x9c
+
 	// MemoryAreaTaskBit Memory area: task flags; bit
 	MemoryAreaTaskBit byte = 0x06
 
 	// MemoryAreaTaskStatus Memory area: task flags; status
 	MemoryAreaTaskStatus byte = 0x46
 
-	// MemoryAreaIndexRegisterPV Memory area: CIO bit
+	// MemoryAreaIndexRegisterPV Memory area: index register PV
 	MemoryAreaIndexRegisterPV byte = 0xdc
 
-	// MemoryAreaDataRegisterPV Memory area: CIO bit
+	// MemoryAreaDataRegisterPV Memory area: data register PV
 	MemoryAreaDataRegisterPV byte = 0xbc
 
-	// MemoryAreaClockPulsesConditionFlagsBit Memory area: CIO bit
+	// MemoryAreaClockPulsesConditionFlagsBit Memory area: clock pulses and condition flags; bit
 	MemoryAreaClockPulsesConditionFlagsBit byte = 0x07
 )
+
+// CounterAddressOffset Offset added to a counter number to address it in the timer and counter memory areas
+const CounterAddressOffset uint16 = 0x8000
+
+// EMBankMax Highest extended memory bank number
+const EMBankMax byte = 0x18
+
+// ErrInvalidEMBank Error when an extended memory bank number is out of range
+var ErrInvalidEMBank = errors.New("extended memory bank number out of range")
+
+// MemoryAreaEMBit Returns the memory area code of the bits of an extended memory bank, 0 to EMBankMax
+func MemoryAreaEMBit(bank byte) (byte, error) {
+	if bank > EMBankMax {
+		return 0, ErrInvalidEMBank
+	}
+	return emBitCode(bank), nil
+}
+
+// MemoryAreaEMWord Returns the memory area code of the words of an extended memory bank, 0 to EMBankMax
+func MemoryAreaEMWord(bank byte) (byte, error) {
+	if bank > EMBankMax {
+		return 0, ErrInvalidEMBank
+	}
+	return emWordCode(bank), nil
+}
+
+// emBitCode Memory area code of the bits of an extended memory bank: 0x20 to 0x2f for banks 0 to F, and 0xe0 to 0xe8
+// for banks 10 to 18 of CJ2 CPU units
+func emBitCode(bank byte) byte {
+	if bank < 0x10 {
+		return 0x20 + bank
+	}
+	return 0xe0 + bank - 0x10
+}
+
+// emWordCode Memory area code of the words of an extended memory bank: 0xa0 to 0xaf for banks 0 to F, and 0x60 to
+// 0x68 for banks 10 to 18 of CJ2 CPU units
+func emWordCode(bank byte) byte {
+	if bank < 0x10 {
+		return 0xa0 + bank
+	}
+	return 0x60 + bank - 0x10
+}
+
+// MemoryAreaElement Kind of the elements of a memory area, which determines how they are addressed and transferred
+type MemoryAreaElement byte
+
+const (
+	// ElementBit Bits addressed by word and bit number, transferred as one byte each
+	ElementBit MemoryAreaElement = iota
+
+	// ElementFlag Flags addressed by number, such as completion flags, transferred as one byte each
+	ElementFlag
+
+	// ElementWord Words, transferred as two bytes each
+	ElementWord
+
+	// ElementDoubleWord Double words, transferred as four bytes each
+	ElementDoubleWord
+
+	// ElementBitForced Bits with their forced status, transferred as one byte each
+	ElementBitForced
+
+	// ElementWordForced Words with their forced bits, transferred as four bytes each
+	ElementWordForced
+)
+
+// Size Returns the number of bytes each element takes in read and write data
+func (e MemoryAreaElement) Size() int {
+	switch e {
+	case ElementWord:
+		return 2
+	case ElementDoubleWord, ElementWordForced:
+		return 4
+	}
+	return 1
+}
+
+// MemoryAreaInfo Description of a memory area of CS and CJ series CPU units
+type MemoryAreaInfo struct {
+	// Name Prefix of the area in addresses, such as CIO, D or E1
+	Name string
+
+	Code    byte
+	Element MemoryAreaElement
+
+	// Offset Added to the element number to form its address, such as CounterAddressOffset for counters
+	Offset uint16
+
+	// First Number of the first word of bit areas, or of the first element of other areas
+	First int
+
+	// Count Number of words of bit areas, or of elements of other areas
+	Count int
+}
+
+// memoryAreaCatalogue Memory areas of CS and CJ series CPU units, with the bits and words of each extended memory
+// bank as returned by MemoryAreaEMBit and MemoryAreaEMWord
+var memoryAreaCatalogue = []MemoryAreaInfo{
+	{"CIO", MemoryAreaCIOBit, ElementBit, 0, 0, 6144},
+	{"CIO", MemoryAreaCIOWord, ElementWord, 0, 0, 6144},
+	{"CIO", MemoryAreaCIOBitForced, ElementBitForced, 0, 0, 6144},
+	{"CIO", MemoryAreaCIOWordForced, ElementWordForced, 0, 0, 6144},
+	{"W", MemoryAreaWRBit, ElementBit, 0, 0, 512},
+	{"W", MemoryAreaWRWord, ElementWord, 0, 0, 512},
+	{"W", MemoryAreaWRBitForced, ElementBitForced, 0, 0, 512},
+	{"W", MemoryAreaWRWordForced, ElementWordForced, 0, 0, 512},
+	{"H", MemoryAreaHRBit, ElementBit, 0, 0, 512},
+	{"H", MemoryAreaHRWord, ElementWord, 0, 0, 512},
+	{"H", MemoryAreaHRBitForced, ElementBitForced, 0, 0, 512},
+	{"H", MemoryAreaHRWordForced, ElementWordForced, 0, 0, 512},
+	{"A", MemoryAreaARBit, ElementBit, 0, 0, 960},
+	{"A", MemoryAreaARWord, ElementWord, 0, 0, 960},
+	{"T", MemoryAreaTimerCounterCompletionFlag, ElementFlag, 0, 0, 4096},
+	{"T", MemoryAreaTimerCounterPV, ElementWord, 0, 0, 4096},
+	{"C", MemoryAreaTimerCounterCompletionFlag, ElementFlag, CounterAddressOffset, 0, 4096},
+	{"C", MemoryAreaTimerCounterPV, ElementWord, CounterAddressOffset, 0, 4096},
+	{"D", MemoryAreaDMBit, ElementBit, 0, 0, 32768},
+	{"D", MemoryAreaDMWord, ElementWord, 0, 0, 32768},
+	{"E", MemoryAreaEMCurrentBit, ElementBit, 0, 0, 32768},
+	{"E", MemoryAreaEMCurrentWord, ElementWord, 0, 0, 32768},
+	{"EMBANK", MemoryAreaEMCurrentBankNumber, ElementWord, 0, 0, 1},
+	{"TK", MemoryAreaTaskBit, ElementFlag, 0, 0, 32},
+	{"TKS", MemoryAreaTaskStatus, ElementFlag, 0, 0, 32},
+	{"IR", MemoryAreaIndexRegisterPV, ElementDoubleWord, 0, 0, 16},
+	{"DR", MemoryAreaDataRegisterPV, ElementWord, 0, 0, 16},
+	{"CF", MemoryAreaClockPulsesConditionFlagsBit, ElementBit, 0, 0, 2},
+	{"E0", 0x20, ElementBit, 0, 0, 32768},
+	{"E0", 0xa0, ElementWord, 0, 0, 32768},
+	{"E1", 0x21, ElementBit, 0, 0, 32768},
+	{"E1", 0xa1, ElementWord, 0, 0, 32768},
+	{"E2", 0x22, ElementBit, 0, 0, 32768},
+	{"E2", 0xa2, ElementWord, 0, 0, 32768},
+	{"E3", 0x23, ElementBit, 0, 0, 32768},
+	{"E3", 0xa3, ElementWord, 0, 0, 32768},
+	{"E4", 0x24, ElementBit, 0, 0, 32768},
+	{"E4", 0xa4, ElementWord, 0, 0, 32768},
+	{"E5", 0x25, ElementBit, 0, 0, 32768},
+	{"E5", 0xa5, ElementWord, 0, 0, 32768},
+	{"E6", 0x26, ElementBit, 0, 0, 32768},
+	{"E6", 0xa6, ElementWord, 0, 0, 32768},
+	{"E7", 0x27, ElementBit, 0, 0, 32768},
+	{"E7", 0xa7, ElementWord, 0, 0, 32768},
+	{"E8", 0x28, ElementBit, 0, 0, 32768},
+	{"E8", 0xa8, ElementWord, 0, 0, 32768},
+	{"E9", 0x29, ElementBit, 0, 0, 32768},
+	{"E9", 0xa9, ElementWord, 0, 0, 32768},
+	{"EA", 0x2a, ElementBit, 0, 0, 32768},
+	{"EA", 0xaa, ElementWord, 0, 0, 32768},
+	{"EB", 0x2b, ElementBit, 0, 0, 32768},
+	{"EB", 0xab, ElementWord, 0, 0, 32768},
+	{"EC", 0x2c, ElementBit, 0, 0, 32768},
+	{"EC", 0xac, ElementWord, 0, 0, 32768},
+	{"ED", 0x2d, ElementBit, 0, 0, 32768},
+	{"ED", 0xad, ElementWord, 0, 0, 32768},
+	{"EE", 0x2e, ElementBit, 0, 0, 32768},
+	{"EE", 0xae, ElementWord, 0, 0, 32768},
+	{"EF", 0x2f, ElementBit, 0, 0, 32768},
+	{"EF", 0xaf, ElementWord, 0, 0, 32768},
+	{"E10", 0xe0, ElementBit, 0, 0, 32768},
+	{"E10", 0x60, ElementWord, 0, 0, 32768},
+	{"E11", 0xe1, ElementBit, 0, 0, 32768},
+	{"E11", 0x61, ElementWord, 0, 0, 32768},
+	{"E12", 0xe2, ElementBit, 0, 0, 32768},
+	{"E12", 0x62, ElementWord, 0, 0, 32768},
+	{"E13", 0xe3, ElementBit, 0, 0, 32768},
+	{"E13", 0x63, ElementWord, 0, 0, 32768},
+	{"E14", 0xe4, ElementBit, 0, 0, 32768},
+	{"E14", 0x64, ElementWord, 0, 0, 32768},
+	{"E15", 0xe5, ElementBit, 0, 0, 32768},
+	{"E15", 0x65, ElementWord, 0, 0, 32768},
+	{"E16", 0xe6, ElementBit, 0, 0, 32768},
+	{"E16", 0x66, ElementWord, 0, 0, 32768},
+	{"E17", 0xe7, ElementBit, 0, 0, 32768},
+	{"E17", 0x67, ElementWord, 0, 0, 32768},
+	{"E18", 0xe8, ElementBit, 0, 0, 32768},
+	{"E18", 0x68, ElementWord, 0, 0, 32768},
+}
+
+// memoryAreaElements Kind of the elements of each memory area code of the catalogue
+var memoryAreaElements = func() map[byte]MemoryAreaElement {
+	elements := make(map[byte]MemoryAreaElement, len(memoryAreaCatalogue))
+	for _, area := range memoryAreaCatalogue {
+		if _, ok := elements[area.Code]; !ok {
+			elements[area.Code] = area.Element
+		}
+	}
+	return elements
+}()
+
+// MemoryAreas Returns the memory areas of CS and CJ series CPU units
+func MemoryAreas() []MemoryAreaInfo {
+	areas := make([]MemoryAreaInfo, len(memoryAreaCatalogue))
+	copy(areas, memoryAreaCatalogue)
+	return areas
+}
+
+// LookupMemoryArea Returns the memory area with the given code that holds the given address, or the first with the
+// code if none holds it. Timers and counters share their memory area codes and are told apart by the address
+func LookupMemoryArea(code byte, address uint16) (*MemoryAreaInfo, bool) {
+	for i := range memoryAreaCatalogue {
+		area := &memoryAreaCatalogue[i]
+		if area.Code == code && area.holds(int(address)-int(area.Offset)) {
+			return area, true
+		}
+	}
+	for i := range memoryAreaCatalogue {
+		if area := &memoryAreaCatalogue[i]; area.Code == code {
+			return area, true
+		}
+	}
+	return nil, false
+}
+
+// holds Reports whether the memory area holds the word or element with the given number
+func (a *MemoryAreaInfo) holds(n int) bool {
+	return n >= a.First && n < a.First+a.Count
+}
+
+// memoryAreaElement Returns the kind of the elements of the memory area with the given code
+func memoryAreaElement(code byte) (MemoryAreaElement, bool) {
+	element, ok := memoryAreaElements[code]
+	return element, ok
+}
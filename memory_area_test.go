@@ -0,0 +1,61 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAreaEM(t *testing.T) {
+	tests := []struct {
+		bank byte
+		bit  byte
+		word byte
+	}{
+		{0x00, 0x20, 0xa0},
+		{0x0b, 0x2b, 0xab},
+		{0x0f, 0x2f, 0xaf},
+		{0x10, 0xe0, 0x60},
+		{0x18, 0xe8, 0x68},
+	}
+	for _, test := range tests {
+		bit, e := MemoryAreaEMBit(test.bank)
+		require.NoError(t, e)
+		assert.Equal(t, test.bit, bit, "bank %X", test.bank)
+		word, e := MemoryAreaEMWord(test.bank)
+		require.NoError(t, e)
+		assert.Equal(t, test.word, word, "bank %X", test.bank)
+	}
+
+	_, e := MemoryAreaEMBit(EMBankMax + 1)
+	assert.True(t, errors.Is(e, ErrInvalidEMBank))
+	_, e = MemoryAreaEMWord(EMBankMax + 1)
+	assert.True(t, errors.Is(e, ErrInvalidEMBank))
+}
+
+func TestMemoryAreaCatalogue(t *testing.T) {
+	// Each bank is named by its number in hexadecimal, for its bits and its words
+	for bank := byte(0); bank <= EMBankMax; bank++ {
+		for _, code := range []byte{emBitCode(bank), emWordCode(bank)} {
+			area, ok := LookupMemoryArea(code, 0)
+			require.True(t, ok, "memory area 0x%02x", code)
+			assert.Equal(t, fmt.Sprintf("E%X", bank), area.Name, "memory area 0x%02x", code)
+		}
+	}
+
+	// Addresses are resolved by name and element, which must not be ambiguous
+	type key struct {
+		name    string
+		element MemoryAreaElement
+	}
+	seen := make(map[key]byte)
+	for _, area := range memoryAreaCatalogue {
+		k := key{area.Name, area.Element}
+		code, ok := seen[k]
+		assert.False(t, ok, "%s element %d is 0x%02x and 0x%02x", area.Name, area.Element, code, area.Code)
+		seen[k] = area.Code
+	}
+}
@@ -0,0 +1,176 @@
+package fins
+
+import (
+	"context"
+	"encoding/binary"
+	"time"
+)
+
+// Message An operator message raised by the MSG instruction
+type Message struct {
+	Number int
+	Text   string
+}
+
+// FALMessage The message of the FAL or FALS instruction that caused the current error
+type FALMessage struct {
+	// Number FAL or FALS number
+	Number uint16
+	Text   string
+}
+
+// MessageFlagsAll Flags selecting all eight messages
+const MessageFlagsAll MessageFlags = 0xff
+
+const (
+	messageRead        uint16 = 0x0000
+	messageClear       uint16 = 0x4000
+	messageFALRead     uint16 = 0x8000
+	messageTextLength         = 32
+	falMessageLength          = 16
+	messageFlagsMask   uint16 = 0x00ff
+	messageCommandMask uint16 = 0xc000
+)
+
+// ReadMessages Reads the selected MSG instruction messages
+func (c *Client) ReadMessages(messages MessageFlags) ([]Message, error) {
+	r, e := c.execute(messageCommand(messageRead | uint16(messages)))
+	if e != nil {
+		return nil, e
+	}
+	if len(r.Data) < 2 {
+		return nil, ErrResponseTooShort
+	}
+
+	flags := MessageFlags(binary.BigEndian.Uint16(r.Data[0:2]) & messageFlagsMask)
+	data := r.Data[2:]
+	result := make([]Message, 0, 8)
+	for n := 0; n < 8; n++ {
+		if !flags.Has(n) {
+			continue
+		}
+		if len(data) < messageTextLength {
+			return nil, ErrResponseTooShort
+		}
+		result = append(result, Message{
+			Number: n,
+			Text:   decodeASCII(data[:messageTextLength]),
+		})
+		data = data[messageTextLength:]
+	}
+	return result, nil
+}
+
+// ClearMessages Clears the selected MSG instruction messages
+func (c *Client) ClearMessages(messages MessageFlags) error {
+	_, e := c.execute(messageCommand(messageClear | uint16(messages)))
+	return e
+}
+
+// ReadFALMessage Reads the FAL or FALS number and message of the current error
+func (c *Client) ReadFALMessage() (*FALMessage, error) {
+	r, e := c.execute(messageCommand(messageFALRead))
+	if e != nil {
+		return nil, e
+	}
+	if len(r.Data) < 2+falMessageLength {
+		return nil, ErrResponseTooShort
+	}
+
+	m := &FALMessage{
+		Number: binary.BigEndian.Uint16(r.Data[0:2]),
+		Text:   decodeASCII(r.Data[2 : 2+falMessageLength]),
+	}
+	return m, nil
+}
+
+func messageCommand(parameter uint16) *Payload {
+	p := &Payload{
+		CommandCode: CommandCodeMessageReadClear,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(p.Data, parameter)
+	return p
+}
+
+// MessageEvent A change to the MSG instruction messages seen by a MessageWatcher
+type MessageEvent struct {
+	Time    time.Time
+	Message Message
+
+	// Cleared True if the message was cleared, false if it appeared or its text changed
+	Cleared bool
+}
+
+// MessageWatcher Polls the MSG instruction messages of a CPU unit and reports messages that appear or are cleared
+type MessageWatcher struct {
+	client   *Client
+	messages map[int]string
+}
+
+// NewMessageWatcher Creates a message watcher. Messages that exist when the watcher first polls are reported as
+// having appeared
+func NewMessageWatcher(client *Client) *MessageWatcher {
+	w := new(MessageWatcher)
+	w.client = client
+	w.messages = make(map[int]string)
+	return w
+}
+
+// Poll Reads the messages and returns the changes since the previous poll
+func (w *MessageWatcher) Poll() ([]MessageEvent, error) {
+	status, e := w.client.ReadCPUUnitStatus()
+	if e != nil {
+		return nil, e
+	}
+	var messages []Message
+	if status.Messages != 0 {
+		messages, e = w.client.ReadMessages(status.Messages)
+		if e != nil {
+			return nil, e
+		}
+	}
+
+	now := time.Now()
+	events := make([]MessageEvent, 0)
+	current := make(map[int]string, len(messages))
+	for _, m := range messages {
+		current[m.Number] = m.Text
+		if text, ok := w.messages[m.Number]; !ok || text != m.Text {
+			events = append(events, MessageEvent{Time: now, Message: m})
+		}
+	}
+	for n := 0; n < 8; n++ {
+		text, ok := w.messages[n]
+		if _, exists := current[n]; ok && !exists {
+			events = append(events, MessageEvent{Time: now, Message: Message{Number: n, Text: text}, Cleared: true})
+		}
+	}
+	w.messages = current
+
+	return events, nil
+}
+
+// Run Polls the messages every interval until the context is done or a read fails, calling onEvent for each change
+func (w *MessageWatcher) Run(ctx context.Context, interval time.Duration, onEvent func(e MessageEvent)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, e := w.Poll()
+		if e != nil {
+			return e
+		}
+		if onEvent != nil {
+			for _, event := range events {
+				onEvent(event)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
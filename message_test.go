@@ -0,0 +1,96 @@
+package fins
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessages(t *testing.T) {
+	s, c := newSimulator(t)
+
+	s.SetMessage(1, "LOW OIL")
+	s.SetMessage(6, "DOOR OPEN")
+	messages, e := c.ReadMessages(0x42)
+	require.NoError(t, e)
+	assert.Equal(t, []Message{{Number: 1, Text: "LOW OIL"}, {Number: 6, Text: "DOOR OPEN"}}, messages)
+	messages, e = c.ReadMessages(MessageFlagsAll)
+	require.NoError(t, e)
+	assert.Len(t, messages, 8)
+
+	// Every selected message is read, the ones that do not exist without text
+	require.NoError(t, c.ClearMessages(0x02))
+	messages, e = c.ReadMessages(0x42)
+	require.NoError(t, e)
+	assert.Equal(t, []Message{{Number: 1}, {Number: 6, Text: "DOOR OPEN"}}, messages)
+
+	m, e := c.ReadFALMessage()
+	require.NoError(t, e)
+	assert.Equal(t, &FALMessage{}, m)
+}
+
+func TestReadFALMessage(t *testing.T) {
+	p, c := newTestClient(func(command *Payload) *Response {
+		return &Response{CommandCode: command.CommandCode, Data: append([]byte{0x00, 0x2a}, "MOTOR OVERLOAD  "...)}
+	})
+	m, e := c.ReadFALMessage()
+	require.NoError(t, e)
+	assert.Equal(t, &FALMessage{Number: 42, Text: "MOTOR OVERLOAD"}, m)
+	assert.Equal(t, []byte{0x80, 0x00}, p.command.Data)
+
+	p.respond = func(command *Payload) *Response {
+		return &Response{CommandCode: command.CommandCode, Data: []byte{0x00, 0x03}}
+	}
+	_, e = c.ReadMessages(MessageFlagsAll)
+	assert.Equal(t, ErrResponseTooShort, e)
+}
+
+func TestMessageWatcher(t *testing.T) {
+	s, c := newSimulator(t)
+	w := NewMessageWatcher(c)
+
+	events, e := w.Poll()
+	require.NoError(t, e)
+	assert.Empty(t, events)
+
+	s.SetMessage(0, "START")
+	s.SetMessage(3, "CHECK")
+	events, e = w.Poll()
+	require.NoError(t, e)
+	require.Len(t, events, 2)
+	assert.Equal(t, Message{Number: 0, Text: "START"}, events[0].Message)
+	assert.Equal(t, Message{Number: 3, Text: "CHECK"}, events[1].Message)
+
+	// A changed text is reported as the message appearing again, and a cleared one with its last text
+	s.SetMessage(0, "RESTART")
+	require.NoError(t, c.ClearMessages(0x08))
+	events, e = w.Poll()
+	require.NoError(t, e)
+	require.Len(t, events, 2)
+	assert.Equal(t, MessageEvent{Time: events[0].Time, Message: Message{Number: 0, Text: "RESTART"}}, events[0])
+	assert.Equal(t, MessageEvent{Time: events[1].Time, Message: Message{Number: 3, Text: "CHECK"}, Cleared: true},
+		events[1])
+
+	events, e = w.Poll()
+	require.NoError(t, e)
+	assert.Empty(t, events)
+}
+
+func TestMessageWatcherRun(t *testing.T) {
+	s, c := newSimulator(t)
+	s.SetMessage(5, "ALARM")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var events []MessageEvent
+	e := NewMessageWatcher(c).Run(ctx, time.Millisecond, func(event MessageEvent) {
+		events = append(events, event)
+		cancel()
+	})
+	assert.True(t, errors.Is(e, context.Canceled), "error %v", e)
+	require.Len(t, events, 1)
+	assert.Equal(t, Message{Number: 5, Text: "ALARM"}, events[0].Message)
+}
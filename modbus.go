@@ -0,0 +1,292 @@
+package fins
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// ModbusReadCoils Modbus function code: read coils
+	ModbusReadCoils byte = 0x01
+
+	// ModbusReadDiscreteInputs Modbus function code: read discrete inputs
+	ModbusReadDiscreteInputs byte = 0x02
+
+	// ModbusReadHoldingRegisters Modbus function code: read holding registers
+	ModbusReadHoldingRegisters byte = 0x03
+
+	// ModbusReadInputRegisters Modbus function code: read input registers
+	ModbusReadInputRegisters byte = 0x04
+
+	// ModbusWriteSingleCoil Modbus function code: write single coil
+	ModbusWriteSingleCoil byte = 0x05
+
+	// ModbusWriteSingleRegister Modbus function code: write single register
+	ModbusWriteSingleRegister byte = 0x06
+
+	// ModbusWriteMultipleCoils Modbus function code: write multiple coils
+	ModbusWriteMultipleCoils byte = 0x0f
+
+	// ModbusWriteMultipleRegisters Modbus function code: write multiple registers
+	ModbusWriteMultipleRegisters byte = 0x10
+)
+
+const (
+	modbusExceptionFlag byte = 0x80
+
+	// modbusReadBitsMax Largest number of coils or discrete inputs read in one request
+	modbusReadBitsMax = 2000
+
+	// modbusReadRegistersMax Largest number of registers read in one request
+	modbusReadRegistersMax = 125
+
+	// modbusWriteBitsMax Largest number of coils written in one request
+	modbusWriteBitsMax = 1968
+
+	// modbusWriteRegistersMax Largest number of registers written in one request
+	modbusWriteRegistersMax = 123
+
+	modbusCoilOn uint16 = 0xff00
+)
+
+// ErrInvalidModbusResponse Error when a Modbus response does not match its request
+var ErrInvalidModbusResponse = errors.New("invalid Modbus response")
+
+// ErrModbusCount Error when the number of coils or registers of a request is outside the range Modbus allows
+var ErrModbusCount = errors.New("number of Modbus coils or registers out of range")
+
+// ModbusPDU A Modbus protocol data unit
+type ModbusPDU struct {
+	Function byte
+	Data     []byte
+}
+
+// ModbusExceptionError Error when a Modbus slave responds with an exception
+type ModbusExceptionError struct {
+	Function      byte
+	ExceptionCode byte
+}
+
+var modbusExceptionCodes = map[byte]string{
+	0x01: "illegal function",
+	0x02: "illegal data address",
+	0x03: "illegal data value",
+	0x04: "slave device failure",
+	0x05: "acknowledge",
+	0x06: "slave device busy",
+	0x08: "memory parity error",
+	0x0a: "gateway path unavailable",
+	0x0b: "gateway target device failed to respond",
+}
+
+func (e *ModbusExceptionError) Error() string {
+	if text, ok := modbusExceptionCodes[e.ExceptionCode]; ok {
+		return fmt.Sprintf("Modbus exception %d on function 0x%02x: %s", e.ExceptionCode, e.Function, text)
+	}
+	return fmt.Sprintf("Modbus exception %d on function 0x%02x", e.ExceptionCode, e.Function)
+}
+
+// Modbus A Modbus slave connected to a serial port of the PLC and reached through the FINS serial gateway
+type Modbus struct {
+	client *Client
+	port   Address
+	slave  byte
+	ascii  bool
+}
+
+// ModbusRTU Returns the Modbus-RTU slave with the given address on the serial port with the given unit address, see
+// SerialUnitPort
+func (c *Client) ModbusRTU(unit byte, slave byte) *Modbus {
+	m := new(Modbus)
+	m.client = c
+	m.port = c.serialPort(unit)
+	m.slave = slave
+	return m
+}
+
+// ModbusASCII Returns the Modbus-ASCII slave with the given address on the serial port with the given unit address,
+// see SerialUnitPort
+func (c *Client) ModbusASCII(unit byte, slave byte) *Modbus {
+	m := c.ModbusRTU(unit, slave)
+	m.ascii = true
+	return m
+}
+
+// Execute Sends a request to the slave and returns its response. A *ModbusExceptionError reports exception responses
+func (m *Modbus) Execute(request *ModbusPDU) (*ModbusPDU, error) {
+	command := &Payload{
+		CommandCode: CommandCodeConvertToModbusRTUCommand,
+		Data:        encodeModbusADU(m.slave, request),
+	}
+	if m.ascii {
+		command.CommandCode = CommandCodeConvertToModbusASCIICommand
+		command.Data = []byte(strings.ToUpper(hex.EncodeToString(command.Data)))
+	}
+	r, e := m.client.executeAt(m.port, command)
+	if e != nil {
+		return nil, e
+	}
+
+	data := r.Data
+	if m.ascii {
+		data, e = hex.DecodeString(string(data))
+		if e != nil {
+			return nil, ErrInvalidModbusResponse
+		}
+	}
+	slave, response, e := decodeModbusADU(data)
+	if e != nil {
+		return nil, e
+	}
+	if slave != m.slave || response.Function&^modbusExceptionFlag != request.Function {
+		return nil, ErrInvalidModbusResponse
+	}
+	if response.Function&modbusExceptionFlag != 0 {
+		if len(response.Data) < 1 {
+			return nil, ErrInvalidModbusResponse
+		}
+		return nil, &ModbusExceptionError{Function: request.Function, ExceptionCode: response.Data[0]}
+	}
+	return response, nil
+}
+
+// ReadCoils Reads count coils starting at address
+func (m *Modbus) ReadCoils(address uint16, count uint16) ([]bool, error) {
+	return m.readBits(ModbusReadCoils, address, count)
+}
+
+// ReadDiscreteInputs Reads count discrete inputs starting at address
+func (m *Modbus) ReadDiscreteInputs(address uint16, count uint16) ([]bool, error) {
+	return m.readBits(ModbusReadDiscreteInputs, address, count)
+}
+
+// ReadHoldingRegisters Reads count holding registers starting at address
+func (m *Modbus) ReadHoldingRegisters(address uint16, count uint16) ([]uint16, error) {
+	return m.readRegisters(ModbusReadHoldingRegisters, address, count)
+}
+
+// ReadInputRegisters Reads count input registers starting at address
+func (m *Modbus) ReadInputRegisters(address uint16, count uint16) ([]uint16, error) {
+	return m.readRegisters(ModbusReadInputRegisters, address, count)
+}
+
+// WriteSingleCoil Writes one coil
+func (m *Modbus) WriteSingleCoil(address uint16, value bool) error {
+	v := uint16(0)
+	if value {
+		v = modbusCoilOn
+	}
+	_, e := m.Execute(modbusAddressValueRequest(ModbusWriteSingleCoil, address, v))
+	return e
+}
+
+// WriteSingleRegister Writes one holding register
+func (m *Modbus) WriteSingleRegister(address uint16, value uint16) error {
+	_, e := m.Execute(modbusAddressValueRequest(ModbusWriteSingleRegister, address, value))
+	return e
+}
+
+// WriteMultipleCoils Writes coils starting at address
+func (m *Modbus) WriteMultipleCoils(address uint16, values []bool) error {
+	if len(values) < 1 || len(values) > modbusWriteBitsMax {
+		return ErrModbusCount
+	}
+	request := modbusAddressValueRequest(ModbusWriteMultipleCoils, address, uint16(len(values)))
+	data := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			data[i/8] |= 1 << uint(i%8)
+		}
+	}
+	request.Data = append(request.Data, byte(len(data)))
+	request.Data = append(request.Data, data...)
+	_, e := m.Execute(request)
+	return e
+}
+
+// WriteMultipleRegisters Writes holding registers starting at address
+func (m *Modbus) WriteMultipleRegisters(address uint16, values []uint16) error {
+	if len(values) < 1 || len(values) > modbusWriteRegistersMax {
+		return ErrModbusCount
+	}
+	request := modbusAddressValueRequest(ModbusWriteMultipleRegisters, address, uint16(len(values)))
+	request.Data = append(request.Data, byte(2*len(values)))
+	for _, v := range values {
+		request.Data = append(request.Data, byte(v>>8), byte(v))
+	}
+	_, e := m.Execute(request)
+	return e
+}
+
+func (m *Modbus) readBits(function byte, address uint16, count uint16) ([]bool, error) {
+	if count < 1 || count > modbusReadBitsMax {
+		return nil, ErrModbusCount
+	}
+	response, e := m.Execute(modbusAddressValueRequest(function, address, count))
+	if e != nil {
+		return nil, e
+	}
+	n := (int(count) + 7) / 8
+	if len(response.Data) < 1 || int(response.Data[0]) != n || len(response.Data) < 1+n {
+		return nil, ErrInvalidModbusResponse
+	}
+
+	values := make([]bool, count)
+	for i := range values {
+		values[i] = response.Data[1+i/8]&(1<<uint(i%8)) != 0
+	}
+	return values, nil
+}
+
+func (m *Modbus) readRegisters(function byte, address uint16, count uint16) ([]uint16, error) {
+	if count < 1 || count > modbusReadRegistersMax {
+		return nil, ErrModbusCount
+	}
+	response, e := m.Execute(modbusAddressValueRequest(function, address, count))
+	if e != nil {
+		return nil, e
+	}
+	if len(response.Data) < 1 || int(response.Data[0]) != 2*int(count) || len(response.Data) < 1+2*int(count) {
+		return nil, ErrInvalidModbusResponse
+	}
+
+	values := make([]uint16, count)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint16(response.Data[1+i*2 : 3+i*2])
+	}
+	return values, nil
+}
+
+// modbusAddressValueRequest Creates a request holding an address followed by a value or count
+func modbusAddressValueRequest(function byte, address uint16, value uint16) *ModbusPDU {
+	request := &ModbusPDU{
+		Function: function,
+		Data:     make([]byte, 4),
+	}
+	binary.BigEndian.PutUint16(request.Data[0:2], address)
+	binary.BigEndian.PutUint16(request.Data[2:4], value)
+	return request
+}
+
+// encodeModbusADU Encodes the slave address and PDU carried by the FINS serial gateway, which adds the checksum and
+// framing of the serial protocol
+func encodeModbusADU(slave byte, pdu *ModbusPDU) []byte {
+	data := make([]byte, 2, 2+len(pdu.Data))
+	data[0] = slave
+	data[1] = pdu.Function
+	return append(data, pdu.Data...)
+}
+
+func decodeModbusADU(data []byte) (byte, *ModbusPDU, error) {
+	if len(data) < 2 {
+		return 0, nil, ErrInvalidModbusResponse
+	}
+	pdu := &ModbusPDU{
+		Function: data[1],
+		Data:     data[2:],
+	}
+	return data[0], pdu, nil
+}
@@ -0,0 +1,181 @@
+package fins
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newModbusTestClient Returns a client whose serial gateway answers Modbus requests with the response in hexadecimal
+// given by reply, as a Modbus-RTU frame without its CRC or as the characters of a Modbus-ASCII frame
+func newModbusTestClient(reply *string) (*testProvider, *Client) {
+	return newTestClient(func(command *Payload) *Response {
+		data, _ := hex.DecodeString(*reply)
+		if command.CommandCode == CommandCodeConvertToModbusASCIICommand {
+			data = []byte(*reply)
+		}
+		return &Response{CommandCode: command.CommandCode, Data: data}
+	})
+}
+
+func TestModbusADU(t *testing.T) {
+	pdu := &ModbusPDU{Function: ModbusReadHoldingRegisters, Data: []byte{0x00, 0x6b, 0x00, 0x03}}
+	adu := encodeModbusADU(0x11, pdu)
+	assert.Equal(t, []byte{0x11, 0x03, 0x00, 0x6b, 0x00, 0x03}, adu)
+
+	slave, decoded, e := decodeModbusADU(adu)
+	require.NoError(t, e)
+	assert.Equal(t, byte(0x11), slave)
+	assert.Equal(t, pdu, decoded)
+
+	_, _, e = decodeModbusADU([]byte{0x11})
+	assert.True(t, errors.Is(e, ErrInvalidModbusResponse))
+}
+
+func TestModbusRTU(t *testing.T) {
+	// Requests and responses of the examples of the Modbus application protocol specification, for slave 0x11
+	tests := []struct {
+		name     string
+		call     func(m *Modbus) (interface{}, error)
+		request  string
+		response string
+		want     interface{}
+	}{
+		{
+			name: "read coils",
+			call: func(m *Modbus) (interface{}, error) {
+				return m.ReadCoils(0x0013, 19)
+			},
+			request:  "110100130013",
+			response: "110103cd6b05",
+			want: []bool{true, false, true, true, false, false, true, true, true, true, false, true, false, true,
+				true, false, true, false, true},
+		},
+		{
+			name: "read discrete inputs",
+			call: func(m *Modbus) (interface{}, error) {
+				return m.ReadDiscreteInputs(0x00c4, 3)
+			},
+			request:  "110200c40003",
+			response: "11020105",
+			want:     []bool{true, false, true},
+		},
+		{
+			name: "read holding registers",
+			call: func(m *Modbus) (interface{}, error) {
+				return m.ReadHoldingRegisters(0x006b, 3)
+			},
+			request:  "1103006b0003",
+			response: "110306022b00000064",
+			want:     []uint16{0x022b, 0x0000, 0x0064},
+		},
+		{
+			name: "read input registers",
+			call: func(m *Modbus) (interface{}, error) {
+				return m.ReadInputRegisters(0x0008, 1)
+			},
+			request:  "110400080001",
+			response: "110402000a",
+			want:     []uint16{0x000a},
+		},
+		{
+			name: "write single coil",
+			call: func(m *Modbus) (interface{}, error) {
+				return nil, m.WriteSingleCoil(0x00ac, true)
+			},
+			request:  "110500acff00",
+			response: "110500acff00",
+		},
+		{
+			name: "write single register",
+			call: func(m *Modbus) (interface{}, error) {
+				return nil, m.WriteSingleRegister(0x0001, 0x0003)
+			},
+			request:  "110600010003",
+			response: "110600010003",
+		},
+		{
+			name: "write multiple coils",
+			call: func(m *Modbus) (interface{}, error) {
+				return nil, m.WriteMultipleCoils(0x0013, []bool{true, false, true, true, false, false, true, true,
+					true, false})
+			},
+			request:  "110f0013000a02cd01",
+			response: "110f0013000a",
+		},
+		{
+			name: "write multiple registers",
+			call: func(m *Modbus) (interface{}, error) {
+				return nil, m.WriteMultipleRegisters(0x0001, []uint16{0x000a, 0x0102})
+			},
+			request:  "11100001000204000a0102",
+			response: "111000010002",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reply := test.response
+			p, c := newModbusTestClient(&reply)
+			v, e := test.call(c.ModbusRTU(SerialUnitPort(1, 2), 0x11))
+			require.NoError(t, e)
+			assert.Equal(t, test.want, v)
+			assert.Equal(t, CommandCodeConvertToModbusRTUCommand, p.command.CommandCode)
+			assert.Equal(t, test.request, hex.EncodeToString(p.command.Data))
+			assert.Equal(t, byte(0x86), p.header.dst.Unit)
+
+			// The same frames as the characters of Modbus-ASCII
+			reply = strings.ToUpper(test.response)
+			v, e = test.call(c.ModbusASCII(SerialUnitPort(1, 2), 0x11))
+			require.NoError(t, e)
+			assert.Equal(t, test.want, v)
+			assert.Equal(t, CommandCodeConvertToModbusASCIICommand, p.command.CommandCode)
+			assert.Equal(t, strings.ToUpper(test.request), string(p.command.Data))
+		})
+	}
+}
+
+func TestModbusErrors(t *testing.T) {
+	var reply string
+	_, c := newModbusTestClient(&reply)
+	m := c.ModbusRTU(SerialUnitPort(0, 1), 0x11)
+
+	reply = "118302"
+	_, e := m.ReadHoldingRegisters(0, 1)
+	var exception *ModbusExceptionError
+	require.True(t, errors.As(e, &exception), "error %v", e)
+	assert.Equal(t, ModbusExceptionError{Function: ModbusReadHoldingRegisters, ExceptionCode: 0x02}, *exception)
+	assert.Equal(t, "Modbus exception 2 on function 0x03: illegal data address", e.Error())
+
+	for _, r := range []string{
+		"1183",           // exception without its code
+		"120302000a",     // another slave
+		"110402000a",     // another function
+		"110304000a000b", // more registers than requested
+		"1103",           // no byte count
+	} {
+		reply = r
+		_, e = m.ReadHoldingRegisters(0, 1)
+		assert.True(t, errors.Is(e, ErrInvalidModbusResponse), "%s: error %v", r, e)
+	}
+	reply = "110102ff"
+	_, e = m.ReadCoils(0, 9)
+	assert.True(t, errors.Is(e, ErrInvalidModbusResponse), "error %v", e)
+
+	reply = "not hexadecimal"
+	_, e = c.ModbusASCII(SerialUnitPort(0, 1), 0x11).ReadHoldingRegisters(0, 1)
+	assert.True(t, errors.Is(e, ErrInvalidModbusResponse), "error %v", e)
+
+	_, e = m.ReadCoils(0, 0)
+	assert.True(t, errors.Is(e, ErrModbusCount))
+	_, e = m.ReadCoils(0, 2001)
+	assert.True(t, errors.Is(e, ErrModbusCount))
+	_, e = m.ReadInputRegisters(0, 126)
+	assert.True(t, errors.Is(e, ErrModbusCount))
+	assert.True(t, errors.Is(m.WriteMultipleCoils(0, make([]bool, 1969)), ErrModbusCount))
+	assert.True(t, errors.Is(m.WriteMultipleRegisters(0, nil), ErrModbusCount))
+	assert.True(t, errors.Is(m.WriteMultipleRegisters(0, make([]uint16, 124)), ErrModbusCount))
+}
@@ -0,0 +1,47 @@
+package fins
+
+import "encoding/binary"
+
+// ReadMultiple Reads one element at each address in one frame with Multiple Memory Area Read, returning bits and flags
+// as 0 or 1 and words and double words as their value
+func (c *Client) ReadMultiple(addresses []IOAddress) ([]uint32, error) {
+	sizes := make([]int, len(addresses))
+	command := &Payload{
+		CommandCode: CommandCodeMultipleMemoryAreaRead,
+		Data:        make([]byte, 0, 4*len(addresses)),
+	}
+	for i, ioAddr := range addresses {
+		element, ok := memoryAreaElement(ioAddr.MemoryArea)
+		if !ok || element == ElementBitForced || element == ElementWordForced {
+			return nil, ErrIncompatibleMemoryArea
+		}
+		sizes[i] = element.Size()
+		command.Data = append(command.Data, encodeIOAddress(ioAddr)...)
+	}
+	r, e := c.execute(command)
+	if e != nil {
+		return nil, e
+	}
+
+	values := make([]uint32, len(addresses))
+	data := r.Data
+	for i, size := range sizes {
+		if len(data) < 1+size {
+			return nil, ErrResponseTooShort
+		}
+		values[i] = decodeElement(data[1 : 1+size])
+		data = data[1+size:]
+	}
+	return values, nil
+}
+
+// decodeElement Decodes an element of read data, a bit or flag of one byte or a word or double word
+func decodeElement(data []byte) uint32 {
+	switch len(data) {
+	case 2:
+		return uint32(binary.BigEndian.Uint16(data))
+	case 4:
+		return binary.BigEndian.Uint32(data)
+	}
+	return uint32(data[0] & 0x01)
+}
@@ -0,0 +1,229 @@
+package fins
+
+import (
+	"fmt"
+	"math"
+)
+
+// WordOrder Order in the PLC memory of the words of values longer than one word
+type WordOrder byte
+
+const (
+	// WordOrderLowFirst The least significant word at the lowest address, as Omron PLCs store them
+	WordOrderLowFirst WordOrder = iota
+
+	// WordOrderHighFirst The most significant word at the lowest address
+	WordOrderHighFirst
+)
+
+// SetWordOrder Sets the order of the words of values longer than one word, WordOrderLowFirst by default
+func (c *Client) SetWordOrder(order WordOrder) {
+	c.Lock()
+	defer c.Unlock()
+	c.wordOrder = order
+}
+
+// WordOrder Returns the order of the words of values longer than one word
+func (c *Client) WordOrder() WordOrder {
+	c.Lock()
+	defer c.Unlock()
+	return c.wordOrder
+}
+
+// joinWords Joins the words of one value in the given order
+func joinWords(words []uint16, order WordOrder) uint64 {
+	var raw uint64
+	for i, w := range words {
+		shift := i
+		if order == WordOrderHighFirst {
+			shift = len(words) - 1 - i
+		}
+		raw |= uint64(w) << (16 * uint(shift))
+	}
+	return raw
+}
+
+// splitWords Splits one value into the given number of words in the given order
+func splitWords(raw uint64, n int, order WordOrder) []uint16 {
+	words := make([]uint16, n)
+	for i := range words {
+		shift := i
+		if order == WordOrderHighFirst {
+			shift = n - 1 - i
+		}
+		words[i] = uint16(raw >> (16 * uint(shift)))
+	}
+	return words
+}
+
+// readValues Reads readCount values of the given number of words each
+func (c *Client) readValues(memoryArea byte, address uint16, readCount uint16, size int) ([]uint64, error) {
+	if int(readCount)*size > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: %d values of %d words", ErrTooManyItems, readCount, size)
+	}
+	words, e := c.ReadWords(memoryArea, address, readCount*uint16(size))
+	if e != nil {
+		return nil, e
+	}
+	order := c.WordOrder()
+	values := make([]uint64, readCount)
+	for i := range values {
+		values[i] = joinWords(words[i*size:(i+1)*size], order)
+	}
+	return values, nil
+}
+
+// writeValues Writes values of the given number of words each
+func (c *Client) writeValues(memoryArea byte, address uint16, values []uint64, size int) error {
+	if len(values)*size > math.MaxUint16 {
+		return fmt.Errorf("%w: %d values of %d words", ErrTooManyItems, len(values), size)
+	}
+	order := c.WordOrder()
+	words := make([]uint16, 0, len(values)*size)
+	for _, v := range values {
+		words = append(words, splitWords(v, size, order)...)
+	}
+	return c.WriteWords(memoryArea, address, words)
+}
+
+// ReadInt16s Reads signed 16-bit integers from the PLC data area
+func (c *Client) ReadInt16s(memoryArea byte, address uint16, readCount uint16) ([]int16, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, 1)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]int16, len(raw))
+	for i, v := range raw {
+		data[i] = int16(v)
+	}
+	return data, nil
+}
+
+// ReadInt32s Reads signed 32-bit integers of two words each from the PLC data area
+func (c *Client) ReadInt32s(memoryArea byte, address uint16, readCount uint16) ([]int32, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, 2)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]int32, len(raw))
+	for i, v := range raw {
+		data[i] = int32(v)
+	}
+	return data, nil
+}
+
+// ReadUint32s Reads unsigned 32-bit integers of two words each from the PLC data area
+func (c *Client) ReadUint32s(memoryArea byte, address uint16, readCount uint16) ([]uint32, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, 2)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]uint32, len(raw))
+	for i, v := range raw {
+		data[i] = uint32(v)
+	}
+	return data, nil
+}
+
+// ReadInt64s Reads signed 64-bit integers of four words each from the PLC data area
+func (c *Client) ReadInt64s(memoryArea byte, address uint16, readCount uint16) ([]int64, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, 4)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]int64, len(raw))
+	for i, v := range raw {
+		data[i] = int64(v)
+	}
+	return data, nil
+}
+
+// ReadUint64s Reads unsigned 64-bit integers of four words each from the PLC data area
+func (c *Client) ReadUint64s(memoryArea byte, address uint16, readCount uint16) ([]uint64, error) {
+	return c.readValues(memoryArea, address, readCount, 4)
+}
+
+// ReadFloat32s Reads IEEE 754 single precision numbers of two words each from the PLC data area
+func (c *Client) ReadFloat32s(memoryArea byte, address uint16, readCount uint16) ([]float32, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, 2)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]float32, len(raw))
+	for i, v := range raw {
+		data[i] = math.Float32frombits(uint32(v))
+	}
+	return data, nil
+}
+
+// ReadFloat64s Reads IEEE 754 double precision numbers of four words each from the PLC data area
+func (c *Client) ReadFloat64s(memoryArea byte, address uint16, readCount uint16) ([]float64, error) {
+	raw, e := c.readValues(memoryArea, address, readCount, 4)
+	if e != nil {
+		return nil, e
+	}
+	data := make([]float64, len(raw))
+	for i, v := range raw {
+		data[i] = math.Float64frombits(v)
+	}
+	return data, nil
+}
+
+// WriteInt16s Writes signed 16-bit integers to the PLC data area
+func (c *Client) WriteInt16s(memoryArea byte, address uint16, data []int16) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = uint64(uint16(v))
+	}
+	return c.writeValues(memoryArea, address, raw, 1)
+}
+
+// WriteInt32s Writes signed 32-bit integers of two words each to the PLC data area
+func (c *Client) WriteInt32s(memoryArea byte, address uint16, data []int32) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = uint64(uint32(v))
+	}
+	return c.writeValues(memoryArea, address, raw, 2)
+}
+
+// WriteUint32s Writes unsigned 32-bit integers of two words each to the PLC data area
+func (c *Client) WriteUint32s(memoryArea byte, address uint16, data []uint32) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = uint64(v)
+	}
+	return c.writeValues(memoryArea, address, raw, 2)
+}
+
+// WriteInt64s Writes signed 64-bit integers of four words each to the PLC data area
+func (c *Client) WriteInt64s(memoryArea byte, address uint16, data []int64) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = uint64(v)
+	}
+	return c.writeValues(memoryArea, address, raw, 4)
+}
+
+// WriteUint64s Writes unsigned 64-bit integers of four words each to the PLC data area
+func (c *Client) WriteUint64s(memoryArea byte, address uint16, data []uint64) error {
+	return c.writeValues(memoryArea, address, data, 4)
+}
+
+// WriteFloat32s Writes IEEE 754 single precision numbers of two words each to the PLC data area
+func (c *Client) WriteFloat32s(memoryArea byte, address uint16, data []float32) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = uint64(math.Float32bits(v))
+	}
+	return c.writeValues(memoryArea, address, raw, 2)
+}
+
+// WriteFloat64s Writes IEEE 754 double precision numbers of four words each to the PLC data area
+func (c *Client) WriteFloat64s(memoryArea byte, address uint16, data []float64) error {
+	raw := make([]uint64, len(data))
+	for i, v := range data {
+		raw[i] = math.Float64bits(v)
+	}
+	return c.writeValues(memoryArea, address, raw, 4)
+}
@@ -0,0 +1,133 @@
+package fins
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// OperatingMode A CPU unit operating mode
+type OperatingMode byte
+
+const (
+	// OperatingModeProgram Operating mode: PROGRAM; the program is not executed
+	OperatingModeProgram OperatingMode = 0x00
+
+	// OperatingModeMonitor Operating mode: MONITOR; the program is executed and may be edited online
+	OperatingModeMonitor OperatingMode = 0x02
+
+	// OperatingModeRun Operating mode: RUN; the program is executed
+	OperatingModeRun OperatingMode = 0x04
+)
+
+// ProgramNumberCurrent Program number selecting the program currently in the CPU unit
+const ProgramNumberCurrent uint16 = 0xffff
+
+func (m OperatingMode) String() string {
+	switch m {
+	case OperatingModeProgram:
+		return "PROGRAM"
+	case OperatingModeMonitor:
+		return "MONITOR"
+	case OperatingModeRun:
+		return "RUN"
+	}
+	return fmt.Sprintf("OperatingMode(0x%02x)", byte(m))
+}
+
+// ErrInvalidOperatingMode Error when an operating mode other than PROGRAM, MONITOR or RUN is requested
+var ErrInvalidOperatingMode = errors.New("invalid operating mode")
+
+// ErrModeChangeNoAccessRight Error when the operating mode cannot be changed because another device holds the access right
+var ErrModeChangeNoAccessRight = errors.New("access right is held by another device")
+
+// ErrModeChangeProgramMissing Error when the operating mode cannot be changed because there is no program
+var ErrModeChangeProgramMissing = errors.New("program missing")
+
+// ErrModeChangeNotExecutable Error when the operating mode cannot be changed from the current operating mode
+var ErrModeChangeNotExecutable = errors.New("not executable in the current operating mode")
+
+// ErrModeChangeUnitError Error when the operating mode cannot be changed because of a unit error
+var ErrModeChangeUnitError = errors.New("unit error")
+
+// Run Changes the CPU unit to RUN mode
+func (c *Client) Run() error {
+	return c.SetOperatingMode(OperatingModeRun)
+}
+
+// Monitor Changes the CPU unit to MONITOR mode
+func (c *Client) Monitor() error {
+	return c.SetOperatingMode(OperatingModeMonitor)
+}
+
+// Stop Changes the CPU unit to PROGRAM mode
+func (c *Client) Stop() error {
+	return c.SetOperatingMode(OperatingModeProgram)
+}
+
+// SetOperatingMode Changes the operating mode of the CPU unit
+func (c *Client) SetOperatingMode(mode OperatingMode) error {
+	return c.SetProgramOperatingMode(ProgramNumberCurrent, mode)
+}
+
+// SetProgramOperatingMode Changes the operating mode of the CPU unit for the given program number. CS/CJ-series
+// CPU units only accept ProgramNumberCurrent
+func (c *Client) SetProgramOperatingMode(programNumber uint16, mode OperatingMode) error {
+	var command *Payload
+	switch mode {
+	case OperatingModeProgram:
+		command = stopCommand(programNumber)
+	case OperatingModeMonitor, OperatingModeRun:
+		command = runCommand(programNumber, mode)
+	default:
+		return ErrInvalidOperatingMode
+	}
+
+	_, e := c.execute(command)
+	return modeChangeError(mode, e)
+}
+
+func runCommand(programNumber uint16, mode OperatingMode) *Payload {
+	p := &Payload{
+		CommandCode: CommandCodeRun,
+		Data:        make([]byte, 3),
+	}
+	binary.BigEndian.PutUint16(p.Data[0:2], programNumber)
+	p.Data[2] = byte(mode)
+	return p
+}
+
+func stopCommand(programNumber uint16) *Payload {
+	p := &Payload{
+		CommandCode: CommandCodeStop,
+		Data:        make([]byte, 2),
+	}
+	binary.BigEndian.PutUint16(p.Data[0:2], programNumber)
+	return p
+}
+
+func modeChangeError(mode OperatingMode, e error) error {
+	var ec *EndCodeError
+	if !errors.As(e, &ec) {
+		return e
+	}
+
+	var reason error
+	switch {
+	case ec.EndCode == EndCodeAccessWriteErrorNoAccessRight:
+		reason = ErrModeChangeNoAccessRight
+	case ec.EndCode == EndCodeProgramMissing,
+		ec.EndCode == EndCodeCannotStartStopTableMissing:
+		reason = ErrModeChangeProgramMissing
+	case ec.EndCode >= EndCodeNotExecutableInCurrentModeNotPossibleDuringExecution &&
+		ec.EndCode <= EndCodeNotExecutableInCurrentModeStepCannotBeExecuted:
+		reason = ErrModeChangeNotExecutable
+	case ec.EndCode >= EndCodeUnitErrorMemoryError &&
+		ec.EndCode <= EndCodeUnitErrorSYSMACBusTerminatorMissing:
+		reason = ErrModeChangeUnitError
+	default:
+		return e
+	}
+
+	return fmt.Errorf("cannot change to %v mode: %w (end code 0x%x)", mode, reason, ec.EndCode)
+}
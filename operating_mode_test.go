@@ -0,0 +1,62 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperatingMode(t *testing.T) {
+	s, c := newSimulator(t)
+	assert.Equal(t, OperatingModeProgram, s.OperatingMode())
+
+	require.NoError(t, c.Run())
+	assert.Equal(t, OperatingModeRun, s.OperatingMode())
+	require.NoError(t, c.Monitor())
+	assert.Equal(t, OperatingModeMonitor, s.OperatingMode())
+	require.NoError(t, c.Stop())
+	assert.Equal(t, OperatingModeProgram, s.OperatingMode())
+
+	assert.True(t, errors.Is(c.SetOperatingMode(OperatingMode(0x01)), ErrInvalidOperatingMode))
+	e := c.SetProgramOperatingMode(1, OperatingModeRun)
+	assert.True(t, errors.Is(e, ErrModeChangeProgramMissing), "error %v", e)
+
+	// Another node holding the access right
+	other := newSimulatorClient(t, s, 3)
+	require.NoError(t, other.AcquireAccessRight())
+	e = c.Run()
+	assert.True(t, errors.Is(e, ErrModeChangeNoAccessRight), "error %v", e)
+	assert.Equal(t, OperatingModeProgram, s.OperatingMode())
+}
+
+func TestModeChangeError(t *testing.T) {
+	tests := []struct {
+		endCode uint16
+		err     error
+	}{
+		{EndCodeAccessWriteErrorNoAccessRight, ErrModeChangeNoAccessRight},
+		{EndCodeProgramMissing, ErrModeChangeProgramMissing},
+		{EndCodeCannotStartStopTableMissing, ErrModeChangeProgramMissing},
+		{EndCodeNotExecutableInCurrentModeNotPossibleDuringExecution, ErrModeChangeNotExecutable},
+		{EndCodeNotExecutableInCurrentModeWrongPLCModeInRun, ErrModeChangeNotExecutable},
+		{EndCodeNotExecutableInCurrentModeStepCannotBeExecuted, ErrModeChangeNotExecutable},
+		{EndCodeUnitErrorMemoryError, ErrModeChangeUnitError},
+		{EndCodeUnitErrorSYSMACBusTerminatorMissing, ErrModeChangeUnitError},
+	}
+	for _, test := range tests {
+		_, c := newTestClient(func(command *Payload) *Response {
+			return &Response{CommandCode: command.CommandCode, EndCode: test.endCode}
+		})
+		e := c.Run()
+		assert.True(t, errors.Is(e, test.err), "end code 0x%04x: error %v", test.endCode, e)
+	}
+
+	_, c := newTestClient(func(command *Payload) *Response {
+		return &Response{CommandCode: command.CommandCode, EndCode: EndCodeCommandTooLong}
+	})
+	var ec *EndCodeError
+	require.True(t, errors.As(c.Stop(), &ec))
+	assert.Equal(t, EndCodeCommandTooLong, ec.EndCode)
+}
@@ -0,0 +1,355 @@
+package fins
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedCommand Error when a command is not supported by the PLC family of the client profile
+var ErrUnsupportedCommand = errors.New("command not supported by the PLC")
+
+// ErrUnsupportedMemoryArea Error when a memory area does not exist in the PLC family of the client profile
+var ErrUnsupportedMemoryArea = errors.New("memory area not supported by the PLC")
+
+// ErrAddressOutOfRange Error when an address is beyond the range of a memory area of the PLC
+var ErrAddressOutOfRange = errors.New("address out of range")
+
+// ErrTooManyItems Error when more items are read or written than fit in one frame
+var ErrTooManyItems = errors.New("too many items for one frame")
+
+// Profile Memory areas, limits and commands of a family of PLCs. Client methods address memory areas with the CS
+// and CJ series memory area codes, which the profile of the client maps to the codes and addresses of its PLC
+// family, checking the address range before sending the command
+type Profile struct {
+	Name string
+
+	// Areas Memory areas of the PLC family, named as in the CS and CJ series catalogue, see MemoryAreas. Areas with
+	// gaps are given as several areas of the same name and element kind
+	Areas []MemoryAreaInfo
+
+	// MaxReadWords Largest number of words read in one frame
+	MaxReadWords int
+
+	// MaxWriteWords Largest number of words written in one frame
+	MaxWriteWords int
+
+	// MaxReadItems Largest number of addresses read in one frame of Multiple Memory Area Read
+	MaxReadItems int
+
+	// Commands Command codes supported, or nil if every command is supported
+	Commands map[uint16]bool
+}
+
+var (
+	// ProfileCSCJ Profile of CS and CJ series CPU units
+	ProfileCSCJ = &Profile{
+		Name:          "CS/CJ",
+		Areas:         MemoryAreas(),
+		MaxReadWords:  999,
+		MaxWriteWords: 996,
+		MaxReadItems:  167,
+		Commands:      csCJCommands,
+	}
+
+	// ProfileCP1 Profile of CP1H and CP1L-M CPU units, which have no extended memory or file memory
+	ProfileCP1 = &Profile{
+		Name:          "CP1H/CP1L-M",
+		Areas:         cp1Areas(32768),
+		MaxReadWords:  999,
+		MaxWriteWords: 996,
+		MaxReadItems:  167,
+		Commands:      cp1Commands,
+	}
+
+	// ProfileCP1LL Profile of CP1L-L CPU units, whose data area holds D0 to D9999 and D32000 to D32767
+	ProfileCP1LL = &Profile{
+		Name:          "CP1L-L",
+		Areas:         cp1Areas(10000),
+		MaxReadWords:  999,
+		MaxWriteWords: 996,
+		MaxReadItems:  167,
+		Commands:      cp1Commands,
+	}
+
+	// ProfileNJNX Profile of NJ and NX series controllers, which expose the CJ series memory areas used for
+	// variables mapped with AT specifications
+	ProfileNJNX = &Profile{
+		Name: "NJ/NX",
+		Areas: profileAreas(func(a *MemoryAreaInfo) bool {
+			switch a.Name {
+			case "CIO", "W", "D":
+			case "H":
+				a.Count = 1536
+			default:
+				return a.Name[0] == 'E' && a.Code != MemoryAreaEMCurrentBankNumber && a.Element != ElementFlag
+			}
+			return a.Element == ElementBit || a.Element == ElementWord
+		}),
+		MaxReadWords:  999,
+		MaxWriteWords: 996,
+		MaxReadItems:  167,
+		Commands: map[uint16]bool{
+			CommandCodeMemoryAreaRead:         true,
+			CommandCodeMemoryAreaWrite:        true,
+			CommandCodeMemoryAreaFill:         true,
+			CommandCodeMultipleMemoryAreaRead: true,
+			CommandCodeMemoryAreaTransfer:     true,
+			CommandCodeRun:                    true,
+			CommandCodeStop:                   true,
+			CommandCodeCPUUnitDataRead:        true,
+			CommandCodeCPUUnitStatusRead:      true,
+			CommandCodeCycleTimeRead:          true,
+			CommandCodeClockRead:              true,
+			CommandCodeClockWrite:             true,
+			CommandCodeErrorClear:             true,
+			CommandCodeErrorLogRead:           true,
+			CommandCodeErrorLogClear:          true,
+		},
+	}
+
+	// ProfileCV Profile of CV series CPU units, which use the CV mode memory area codes
+	ProfileCV = &Profile{
+		Name:          "CV",
+		Areas:         cvAreas(),
+		MaxReadWords:  999,
+		MaxWriteWords: 996,
+		MaxReadItems:  167,
+	}
+)
+
+// csCJCommands Command codes of CS and CJ series CPU units, all but the memory cassette transfer of CP1 CPU units
+var csCJCommands = func() map[uint16]bool {
+	commands := make(map[uint16]bool)
+	for _, code := range allCommandCodes {
+		if code != CommandCodeMemoryCassetteTransfer {
+			commands[code] = true
+		}
+	}
+	return commands
+}()
+
+// cp1Commands Command codes of CP1 CPU units, which have no file memory but a memory cassette
+var cp1Commands = func() map[uint16]bool {
+	commands := make(map[uint16]bool)
+	for _, code := range allCommandCodes {
+		if code>>8 != 0x22 || code == CommandCodeMemoryCassetteTransfer {
+			commands[code] = true
+		}
+	}
+	return commands
+}()
+
+// allCommandCodes Command codes of CS, CJ and CP1 series CPU units
+var allCommandCodes = []uint16{
+	CommandCodeMemoryAreaRead, CommandCodeMemoryAreaWrite, CommandCodeMemoryAreaFill,
+	CommandCodeMultipleMemoryAreaRead, CommandCodeMemoryAreaTransfer,
+	CommandCodeParameterAreaRead, CommandCodeParameterAreaWrite, CommandCodeParameterAreaClear,
+	CommandCodeProgramAreaRead, CommandCodeProgramAreaWrite, CommandCodeProgramAreaClear,
+	CommandCodeRun, CommandCodeStop,
+	CommandCodeCPUUnitDataRead, CommandCodeConnectionDataRead, CommandCodeCPUUnitStatusRead,
+	CommandCodeCycleTimeRead, CommandCodeClockRead, CommandCodeClockWrite, CommandCodeMessageReadClear,
+	CommandCodeAccessRightAcquire, CommandCodeAccessRightForcedAcquire, CommandCodeAccessRightRelease,
+	CommandCodeErrorClear, CommandCodeErrorLogRead, CommandCodeErrorLogClear,
+	CommandCodeFINSWriteAccessLogRead, CommandCodeFINSWriteAccessLogClear,
+	CommandCodeFileNameRead, CommandCodeSingleFileRead, CommandCodeSingleFileWrite, CommandCodeFileMemoryFormat,
+	CommandCodeFileDelete, CommandCodeFileCopy, CommandCodeFileNameChange, CommandCodeMemoryAreaFileTransfer,
+	CommandCodeParameterAreaFileTransfer, CommandCodeProgramAreaFileTransfer, CommandCodeDirectoryCreateDelete,
+	CommandCodeMemoryCassetteTransfer,
+	CommandCodeForcedSetReset, CommandCodeForcedSetResetCancel,
+	CommandCodeConvertToCompoWayFCommand, CommandCodeConvertToModbusRTUCommand,
+	CommandCodeConvertToModbusASCIICommand,
+}
+
+// profileAreas Returns the memory areas of the CS and CJ series catalogue that include accepts, which may adjust them
+func profileAreas(include func(a *MemoryAreaInfo) bool) []MemoryAreaInfo {
+	areas := make([]MemoryAreaInfo, 0)
+	for _, area := range memoryAreaCatalogue {
+		if include(&area) {
+			areas = append(areas, area)
+		}
+	}
+	return areas
+}
+
+func cp1Areas(dmWords int) []MemoryAreaInfo {
+	areas := profileAreas(func(a *MemoryAreaInfo) bool {
+		if a.Name == "D" {
+			a.Count = dmWords
+		}
+		return a.Name[0] != 'E' && a.Name != "IR" && a.Name != "DR"
+	})
+	if dmWords < 32000 {
+		areas = append(areas,
+			MemoryAreaInfo{"D", MemoryAreaDMBit, ElementBit, 0, 32000, 768},
+			MemoryAreaInfo{"D", MemoryAreaDMWord, ElementWord, 0, 32000, 768},
+		)
+	}
+	return areas
+}
+
+func cvAreas() []MemoryAreaInfo {
+	areas := []MemoryAreaInfo{
+		{"CIO", 0x00, ElementBit, 0, 0, 2556},
+		{"CIO", 0x80, ElementWord, 0, 0, 2556},
+		{"CIO", 0x40, ElementBitForced, 0, 0, 2556},
+		{"CIO", 0xc0, ElementWordForced, 0, 0, 2556},
+		{"A", 0x00, ElementBit, 0x0b00, 0, 512},
+		{"A", 0x80, ElementWord, 0x0b00, 0, 512},
+		{"T", 0x01, ElementFlag, 0, 0, 1024},
+		{"T", 0x81, ElementWord, 0, 0, 1024},
+		{"C", 0x01, ElementFlag, 0x0800, 0, 1024},
+		{"C", 0x81, ElementWord, 0x0800, 0, 1024},
+		{"D", 0x82, ElementWord, 0, 0, 32768},
+		{"E", 0x98, ElementWord, 0, 0, 32768},
+	}
+	for bank := byte(0); bank < 8; bank++ {
+		areas = append(areas, MemoryAreaInfo{fmt.Sprintf("E%X", bank), 0x90 + bank, ElementWord, 0, 0, 32768})
+	}
+	return areas
+}
+
+// Supports Reports whether the PLC family supports the command
+func (p *Profile) Supports(commandCode uint16) bool {
+	return p.Commands == nil || p.Commands[commandCode]
+}
+
+// Area Returns the memory area of the PLC family with the given name and element kind holding the word or element
+// with number n
+func (p *Profile) Area(name string, element MemoryAreaElement, n int) (*MemoryAreaInfo, bool) {
+	for i := range p.Areas {
+		area := &p.Areas[i]
+		if area.Name == name && area.Element == element && area.holds(n) {
+			return area, true
+		}
+	}
+	return nil, false
+}
+
+// resolve Maps an address given with a CS and CJ series memory area code to the memory area code and address of the
+// PLC family, checking that count elements from it exist. Codes outside the catalogue are passed unchanged
+func (p *Profile) resolve(ioAddr IOAddress, count int) (IOAddress, error) {
+	cs, ok := LookupMemoryArea(ioAddr.MemoryArea, ioAddr.Address)
+	if !ok {
+		return ioAddr, nil
+	}
+
+	first := int(ioAddr.Address) - int(cs.Offset)
+	last := first
+	if count > 0 {
+		last = first + count - 1
+	}
+	if cs.Element == ElementBit || cs.Element == ElementBitForced {
+		last = first + (int(ioAddr.BitOffset)+count-1)/16
+	}
+
+	if !p.hasArea(cs.Name, cs.Element) {
+		return ioAddr, fmt.Errorf("%w: %s area on %s", ErrUnsupportedMemoryArea, cs.Name, p.Name)
+	}
+	area, ok := p.Area(cs.Name, cs.Element, first)
+	if !ok {
+		return ioAddr, fmt.Errorf("%w: %s%d on %s", ErrAddressOutOfRange, cs.Name, first, p.Name)
+	}
+	if !area.holds(last) {
+		return ioAddr, fmt.Errorf("%w: %s%d on %s", ErrAddressOutOfRange, cs.Name, last, p.Name)
+	}
+
+	resolved := IOAddress{
+		MemoryArea: area.Code,
+		Address:    area.Offset + uint16(first),
+		BitOffset:  ioAddr.BitOffset,
+	}
+	return resolved, nil
+}
+
+func (p *Profile) hasArea(name string, element MemoryAreaElement) bool {
+	for _, area := range p.Areas {
+		if area.Name == name && area.Element == element {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare Checks that the PLC family supports a command and maps the memory area addresses of memory area commands,
+// returning the command to send
+func (p *Profile) prepare(command *Payload) (*Payload, error) {
+	if !p.Supports(command.CommandCode) {
+		return nil, fmt.Errorf("%w: command 0x%04x on %s", ErrUnsupportedCommand, command.CommandCode, p.Name)
+	}
+
+	switch command.CommandCode {
+	case CommandCodeMemoryAreaRead, CommandCodeMemoryAreaWrite, CommandCodeMemoryAreaFill:
+		if len(command.Data) < 6 {
+			return command, nil
+		}
+		data := append([]byte{}, command.Data...)
+		ioAddr := decodeIOAddress(data[0:4])
+		count := int(binary.BigEndian.Uint16(data[4:6]))
+		if e := p.checkFrameSize(command.CommandCode, ioAddr.MemoryArea, count); e != nil {
+			return nil, e
+		}
+		resolved, e := p.resolve(ioAddr, count)
+		if e != nil {
+			return nil, e
+		}
+		copy(data[0:4], encodeIOAddress(resolved))
+		return &Payload{CommandCode: command.CommandCode, Data: data}, nil
+
+	case CommandCodeMultipleMemoryAreaRead:
+		if p.MaxReadItems > 0 && len(command.Data)/4 > p.MaxReadItems {
+			return nil, fmt.Errorf("%w: %d addresses, at most %d on %s", ErrTooManyItems, len(command.Data)/4,
+				p.MaxReadItems, p.Name)
+		}
+		data := append([]byte{}, command.Data...)
+		for i := 0; i+4 <= len(data); i += 4 {
+			resolved, e := p.resolve(decodeIOAddress(data[i:i+4]), 1)
+			if e != nil {
+				return nil, e
+			}
+			copy(data[i:i+4], encodeIOAddress(resolved))
+		}
+		return &Payload{CommandCode: command.CommandCode, Data: data}, nil
+
+	case CommandCodeForcedSetReset:
+		data := append([]byte{}, command.Data...)
+		for i := 4; i+4 <= len(data); i += forcedBitLength {
+			resolved, e := p.resolve(decodeIOAddress(data[i:i+4]), 1)
+			if e != nil {
+				return nil, e
+			}
+			copy(data[i:i+4], encodeIOAddress(resolved))
+		}
+		return &Payload{CommandCode: command.CommandCode, Data: data}, nil
+	}
+	return command, nil
+}
+
+// checkFrameSize Checks that count elements of a memory area fit in one frame of a read or write
+func (p *Profile) checkFrameSize(commandCode uint16, memoryArea byte, count int) error {
+	max := p.frameElements(commandCode, memoryArea)
+	if max > 0 && count > max {
+		return fmt.Errorf("%w: %d elements, at most %d of memory area 0x%02x on %s", ErrTooManyItems, count, max,
+			memoryArea, p.Name)
+	}
+	return nil
+}
+
+// frameElements Returns the largest number of elements of a memory area read or written in one frame, or 0 when the
+// profile sets no limit
+func (p *Profile) frameElements(commandCode uint16, memoryArea byte) int {
+	var max int
+	switch commandCode {
+	case CommandCodeMemoryAreaRead:
+		max = p.MaxReadWords
+	case CommandCodeMemoryAreaWrite:
+		max = p.MaxWriteWords
+	default:
+		return 0
+	}
+	size := 2
+	if element, ok := memoryAreaElement(memoryArea); ok {
+		size = element.Size()
+	}
+	return 2 * max / size
+}
@@ -0,0 +1,38 @@
+package fins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileAreas(t *testing.T) {
+	tests := []struct {
+		profile *Profile
+		name    string
+		element MemoryAreaElement
+		want    bool
+	}{
+		{ProfileCSCJ, "EB", ElementWord, true},
+		{ProfileCSCJ, "EMBANK", ElementWord, true},
+		{ProfileNJNX, "EB", ElementWord, true},
+		{ProfileNJNX, "EB", ElementBit, true},
+		{ProfileNJNX, "E18", ElementWord, true},
+		{ProfileNJNX, "EMBANK", ElementWord, false},
+		{ProfileNJNX, "A", ElementWord, false},
+		{ProfileCP1, "E0", ElementWord, false},
+		{ProfileCP1, "D", ElementWord, true},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.profile.hasArea(test.name, test.element), "%s on %s", test.name,
+			test.profile.Name)
+	}
+}
+
+func TestProfileCommands(t *testing.T) {
+	assert.True(t, ProfileCP1.Supports(CommandCodeMemoryCassetteTransfer))
+	assert.False(t, ProfileCP1.Supports(CommandCodeFileNameRead))
+	assert.False(t, ProfileCSCJ.Supports(CommandCodeMemoryCassetteTransfer))
+	assert.True(t, ProfileCSCJ.Supports(CommandCodeFileNameRead))
+	assert.False(t, ProfileNJNX.Supports(CommandCodeProgramAreaWrite))
+}
@@ -0,0 +1,469 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReadItem Elements read by a read plan: Count bits or flags from Address in bit memory areas and flag areas, or
+// Count words in word memory areas
+type ReadItem struct {
+	Address IOAddress
+	Count   int
+}
+
+// ReadPlanOptions Options of the read plans of a client
+type ReadPlanOptions struct {
+	// MaxGap Largest number of unused words, or bits and flags in areas without words, read to join two ranges of a
+	// memory area into one range read
+	MaxGap int
+
+	// NoMultipleRead Reads every range with Memory Area Read, even when Multiple Memory Area Read takes fewer frames
+	NoMultipleRead bool
+}
+
+// defaultReadPlanOptions Options of the read plans of new clients
+var defaultReadPlanOptions = ReadPlanOptions{MaxGap: 32}
+
+// readPlanCacheSize Number of read plans a client keeps before it forgets them all
+const readPlanCacheSize = 64
+
+// ErrInvalidReadItem Error when a read item has no elements or is in a memory area a read plan cannot read
+var ErrInvalidReadItem = errors.New("invalid read item")
+
+// ErrNotRead Error when a snapshot does not hold the elements asked for
+var ErrNotRead = errors.New("the elements were not read by the read plan")
+
+// planSpan Elements start to end of a memory area. Bits of bit memory areas with a word memory area are spanned by
+// the words of that area, other bits are numbered from bit 0 of word 0
+type planSpan struct {
+	code  byte
+	size  int
+	bits  bool
+	start int
+	end   int
+}
+
+// ioAddress Returns the address of an element of the span
+func (s *planSpan) ioAddress(n int) IOAddress {
+	if s.bits {
+		return IOAddress{MemoryArea: s.code, Address: uint16(n / 16), BitOffset: byte(n % 16)}
+	}
+	return IOAddress{MemoryArea: s.code, Address: uint16(n)}
+}
+
+// planWordMemoryAreas Word memory area holding the bits of each bit memory area other than the extended memory banks
+var planWordMemoryAreas = map[byte]byte{
+	MemoryAreaCIOBit:       MemoryAreaCIOWord,
+	MemoryAreaWRBit:        MemoryAreaWRWord,
+	MemoryAreaHRBit:        MemoryAreaHRWord,
+	MemoryAreaARBit:        MemoryAreaARWord,
+	MemoryAreaDMBit:        MemoryAreaDMWord,
+	MemoryAreaEMCurrentBit: MemoryAreaEMCurrentWord,
+}
+
+// wordMemoryArea Returns the word memory area holding the bits of a bit memory area
+func wordMemoryArea(code byte) (byte, bool) {
+	if word, ok := planWordMemoryAreas[code]; ok {
+		return word, true
+	}
+	if bank, ok := emBank(code); ok && code == emBitCode(bank) {
+		return emWordCode(bank), true
+	}
+	return 0, false
+}
+
+// readSpan Returns the span of elements holding count elements from ioAddr
+func readSpan(ioAddr IOAddress, count int) (planSpan, error) {
+	element, ok := memoryAreaElement(ioAddr.MemoryArea)
+	if count < 1 || !ok {
+		return planSpan{}, fmt.Errorf("%w: %d elements of %v", ErrInvalidReadItem, count, ioAddr)
+	}
+	switch element {
+	case ElementBit:
+		first := int(ioAddr.Address)*16 + int(ioAddr.BitOffset)
+		if code, ok := wordMemoryArea(ioAddr.MemoryArea); ok {
+			return planSpan{code: code, size: 2, start: first / 16, end: (first+count-1)/16 + 1}, nil
+		}
+		return planSpan{code: ioAddr.MemoryArea, size: 1, bits: true, start: first, end: first + count}, nil
+	case ElementFlag, ElementWord:
+		start := int(ioAddr.Address)
+		return planSpan{code: ioAddr.MemoryArea, size: element.Size(), start: start, end: start + count}, nil
+	}
+	return planSpan{}, fmt.Errorf("%w: %d elements of %v", ErrInvalidReadItem, count, ioAddr)
+}
+
+// ReadPlan Frames reading a set of read items, built once by PlanRead and sent at every poll by ExecuteReadPlan.
+// Items of a memory area close to each other are joined into range reads of Memory Area Read, and the ranges that
+// would take more frames that way are read element by element with Multiple Memory Area Read
+type ReadPlan struct {
+	blocks  []planSpan
+	ranges  []int
+	frames  [][]planElement
+	count   int
+	profile *Profile
+	options ReadPlanOptions
+}
+
+// planElement An element read with Multiple Memory Area Read, at offset from the start of a block
+type planElement struct {
+	block  int
+	offset int
+}
+
+// Frames Returns the number of frames the plan sends
+func (p *ReadPlan) Frames() int {
+	return p.count
+}
+
+// buildReadPlan Builds the plan reading a set of read items with the given profile
+func buildReadPlan(items []ReadItem, profile *Profile, options ReadPlanOptions) (*ReadPlan, error) {
+	spans := make([]planSpan, len(items))
+	for i, item := range items {
+		var e error
+		spans[i], e = readSpan(item.Address, item.Count)
+		if e != nil {
+			return nil, e
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].code != spans[j].code {
+			return spans[i].code < spans[j].code
+		}
+		return spans[i].start < spans[j].start
+	})
+
+	// Join overlapping spans into runs, and runs separated by at most MaxGap elements into blocks
+	var runs []planSpan
+	for _, span := range spans {
+		if n := len(runs) - 1; n >= 0 && runs[n].code == span.code && span.start <= runs[n].end {
+			runs[n].end = maxInt(runs[n].end, span.end)
+			continue
+		}
+		runs = append(runs, span)
+	}
+	type planBlock struct {
+		span planSpan
+		runs []planSpan
+		used int
+	}
+	var blocks []planBlock
+	for _, run := range runs {
+		if n := len(blocks) - 1; n >= 0 && blocks[n].span.code == run.code &&
+			run.start-blocks[n].span.end <= options.MaxGap {
+			blocks[n].span.end = run.end
+			blocks[n].runs = append(blocks[n].runs, run)
+			blocks[n].used += run.end - run.start
+			continue
+		}
+		blocks = append(blocks, planBlock{span: run, runs: []planSpan{run}, used: run.end - run.start})
+	}
+
+	rangeFrames := func(span planSpan) int {
+		max := profile.frameElements(CommandCodeMemoryAreaRead, span.code)
+		if max < 1 {
+			return 1
+		}
+		return (span.end - span.start + max - 1) / max
+	}
+
+	// Move the blocks with the fewest elements to Multiple Memory Area Read while it saves frames
+	order := make([]int, len(blocks))
+	frames := 0
+	for i := range blocks {
+		order[i] = i
+		frames += rangeFrames(blocks[i].span)
+	}
+	moved := 0
+	maxItems := profile.MaxReadItems
+	if !options.NoMultipleRead && maxItems > 0 && profile.Supports(CommandCodeMultipleMemoryAreaRead) {
+		sort.SliceStable(order, func(i, j int) bool { return blocks[order[i]].used < blocks[order[j]].used })
+		best, remaining, elements := frames, frames, 0
+		for m := 1; m <= len(order); m++ {
+			b := &blocks[order[m-1]]
+			remaining -= rangeFrames(b.span)
+			elements += b.used
+			if n := remaining + (elements+maxItems-1)/maxItems; n < best {
+				best, moved = n, m
+			}
+		}
+		frames = best
+	}
+
+	p := &ReadPlan{
+		count:   frames,
+		profile: profile,
+		options: options,
+	}
+	var frame []planElement
+	for m, i := range order {
+		b := &blocks[i]
+		if m >= moved {
+			p.ranges = append(p.ranges, len(p.blocks))
+			p.blocks = append(p.blocks, b.span)
+			continue
+		}
+		for _, run := range b.runs {
+			for n := run.start; n < run.end; n++ {
+				frame = append(frame, planElement{block: len(p.blocks), offset: n - run.start})
+				if len(frame) == maxItems {
+					p.frames = append(p.frames, frame)
+					frame = nil
+				}
+			}
+			p.blocks = append(p.blocks, run)
+		}
+	}
+	if len(frame) > 0 {
+		p.frames = append(p.frames, frame)
+	}
+
+	// Sort the blocks by memory area and start for snapshots to search them
+	sorted := make([]int, len(p.blocks))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := &p.blocks[sorted[i]], &p.blocks[sorted[j]]
+		if a.code != b.code {
+			return a.code < b.code
+		}
+		return a.start < b.start
+	})
+	index := make([]int, len(sorted))
+	spans = make([]planSpan, len(sorted))
+	for i, n := range sorted {
+		index[n] = i
+		spans[i] = p.blocks[n]
+	}
+	p.blocks = spans
+	for i := range p.ranges {
+		p.ranges[i] = index[p.ranges[i]]
+	}
+	for _, frame := range p.frames {
+		for i := range frame {
+			frame[i].block = index[frame[i].block]
+		}
+	}
+	return p, nil
+}
+
+// readPlanKey Returns the key of a set of read items in the read plan cache
+func readPlanKey(items []ReadItem) string {
+	var key strings.Builder
+	for _, item := range items {
+		fmt.Fprintf(&key, "%02x:%d.%d*%d;", item.Address.MemoryArea, item.Address.Address, item.Address.BitOffset,
+			item.Count)
+	}
+	return key.String()
+}
+
+// SetReadPlanOptions Sets the options of the read plans of the client, a MaxGap of 32 by default
+func (c *Client) SetReadPlanOptions(options ReadPlanOptions) {
+	c.Lock()
+	defer c.Unlock()
+	c.readPlanOptions = options
+}
+
+// ReadPlanOptions Returns the options of the read plans of the client
+func (c *Client) ReadPlanOptions() ReadPlanOptions {
+	c.Lock()
+	defer c.Unlock()
+	return c.readPlanOptions
+}
+
+// PlanRead Returns the plan reading a set of read items with the profile and read plan options of the client. Plans
+// are cached by their read items, so that polling the same items plans them once
+func (c *Client) PlanRead(items []ReadItem) (*ReadPlan, error) {
+	key := readPlanKey(items)
+	profile := c.Profile()
+	options := c.ReadPlanOptions()
+
+	c.Lock()
+	p, ok := c.readPlans[key]
+	c.Unlock()
+	if ok && p.profile == profile && p.options == options {
+		return p, nil
+	}
+
+	p, e := buildReadPlan(items, profile, options)
+	if e != nil {
+		return nil, e
+	}
+	c.Lock()
+	defer c.Unlock()
+	if c.readPlans == nil || len(c.readPlans) >= readPlanCacheSize {
+		c.readPlans = make(map[string]*ReadPlan)
+	}
+	c.readPlans[key] = p
+	return p, nil
+}
+
+// Snapshot Elements read by a read plan at one poll
+type Snapshot struct {
+	plan   *ReadPlan
+	values [][]uint32
+}
+
+// ExecuteReadPlan Sends the frames of a read plan, up to ParallelFrames at once, and returns the elements read
+func (c *Client) ExecuteReadPlan(p *ReadPlan) (*Snapshot, error) {
+	s := &Snapshot{
+		plan:   p,
+		values: make([][]uint32, len(p.blocks)),
+	}
+	for i, block := range p.blocks {
+		s.values[i] = make([]uint32, block.end-block.start)
+	}
+
+	e := c.sendParallel(len(p.ranges)+len(p.frames), func(i int) error {
+		if i < len(p.ranges) {
+			block := &p.blocks[p.ranges[i]]
+			data, e := c.readMemoryArea(block.ioAddress(block.start), block.end-block.start, block.size)
+			if e != nil {
+				return e
+			}
+			values := s.values[p.ranges[i]]
+			for n := range values {
+				values[n] = decodeElement(data[n*block.size : (n+1)*block.size])
+			}
+			return nil
+		}
+
+		frame := p.frames[i-len(p.ranges)]
+		addresses := make([]IOAddress, len(frame))
+		for n, element := range frame {
+			block := &p.blocks[element.block]
+			addresses[n] = block.ioAddress(block.start + element.offset)
+		}
+		values, e := c.ReadMultiple(addresses)
+		if e != nil {
+			return e
+		}
+		for n, element := range frame {
+			s.values[element.block][element.offset] = values[n]
+		}
+		return nil
+	})
+	if e != nil {
+		return nil, e
+	}
+	return s, nil
+}
+
+// ReadItems Reads a set of read items with their cached read plan
+func (c *Client) ReadItems(items []ReadItem) (*Snapshot, error) {
+	p, e := c.PlanRead(items)
+	if e != nil {
+		return nil, e
+	}
+	return c.ExecuteReadPlan(p)
+}
+
+// elements Returns the elements of a span held by the snapshot
+func (s *Snapshot) elements(span planSpan) ([]uint32, error) {
+	blocks := s.plan.blocks
+	i := sort.Search(len(blocks), func(i int) bool {
+		return blocks[i].code > span.code || blocks[i].code == span.code && blocks[i].start > span.start
+	}) - 1
+	for ; i >= 0 && blocks[i].code == span.code; i-- {
+		if blocks[i].bits == span.bits && blocks[i].start <= span.start && span.end <= blocks[i].end {
+			return s.values[i][span.start-blocks[i].start : span.end-blocks[i].start], nil
+		}
+	}
+	return nil, ErrNotRead
+}
+
+// Words Returns words of a word memory area held by the snapshot
+func (s *Snapshot) Words(memoryArea byte, address uint16, count int) ([]uint16, error) {
+	if !checkIsWordMemoryArea(memoryArea) {
+		return nil, ErrIncompatibleMemoryArea
+	}
+	span, e := readSpan(IOAddress{MemoryArea: memoryArea, Address: address}, count)
+	if e != nil {
+		return nil, e
+	}
+	values, e := s.elements(span)
+	if e != nil {
+		return nil, e
+	}
+	words := make([]uint16, count)
+	for i := range words {
+		words[i] = uint16(values[i])
+	}
+	return words, nil
+}
+
+// Bits Returns bits of a bit memory area, or flags of a flag area, held by the snapshot
+func (s *Snapshot) Bits(memoryArea byte, address uint16, bitOffset byte, count int) ([]bool, error) {
+	if !checkIsBitMemoryArea(memoryArea) {
+		return nil, ErrIncompatibleMemoryArea
+	}
+	ioAddr := IOAddress{MemoryArea: memoryArea, Address: address, BitOffset: bitOffset}
+	span, e := readSpan(ioAddr, count)
+	if e != nil {
+		return nil, e
+	}
+	values, e := s.elements(span)
+	if e != nil {
+		return nil, e
+	}
+	bits := make([]bool, count)
+	for i := range bits {
+		if span.size == 2 {
+			n := int(address)*16 + int(bitOffset) + i
+			bits[i] = values[n/16-span.start]&(1<<uint(n%16)) != 0
+		} else {
+			bits[i] = values[i] != 0
+		}
+	}
+	return bits, nil
+}
+
+// tagReadItem Returns the read item of the elements of a tag
+func tagReadItem(tag *Tag) ReadItem {
+	if tag.DataType == DataTypeBool {
+		return ReadItem{Address: tag.Address, Count: tag.elements()}
+	}
+	return ReadItem{Address: tag.Address, Count: tag.Words()}
+}
+
+// ReadTags Reads the values of tags with the cached read plan of their addresses, see ReadTag
+func (c *Client) ReadTags(names ...string) (map[string]interface{}, error) {
+	tags := make([]Tag, len(names))
+	items := make([]ReadItem, len(names))
+	for i, name := range names {
+		var e error
+		tags[i], e = c.lookupTag(name)
+		if e != nil {
+			return nil, e
+		}
+		items[i] = tagReadItem(&tags[i])
+	}
+	s, e := c.ReadItems(items)
+	if e != nil {
+		return nil, e
+	}
+
+	order, options := c.WordOrder(), c.StringOptions()
+	values := make(map[string]interface{}, len(tags))
+	for i := range tags {
+		tag := &tags[i]
+		var bits []bool
+		var words []uint16
+		if tag.DataType == DataTypeBool {
+			bits, e = s.Bits(tag.Address.MemoryArea, tag.Address.Address, tag.Address.BitOffset, items[i].Count)
+		} else {
+			words, e = s.Words(tag.Address.MemoryArea, tag.Address.Address, items[i].Count)
+		}
+		if e != nil {
+			return nil, e
+		}
+		values[tag.Name], e = tag.decode(bits, words, order, options)
+		if e != nil {
+			return nil, e
+		}
+	}
+	return values, nil
+}
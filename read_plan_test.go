@@ -0,0 +1,296 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func wordItem(address uint16, count int) ReadItem {
+	return ReadItem{Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: address}, Count: count}
+}
+
+func bitItem(memoryArea byte, address uint16, bitOffset byte, count int) ReadItem {
+	return ReadItem{Address: IOAddress{MemoryArea: memoryArea, Address: address, BitOffset: bitOffset}, Count: count}
+}
+
+// profileWithout Returns a copy of the CS/CJ profile without the given command
+func profileWithout(commandCode uint16) *Profile {
+	p := *ProfileCSCJ
+	p.Commands = make(map[uint16]bool)
+	for code := range csCJCommands {
+		p.Commands[code] = code != commandCode
+	}
+	return &p
+}
+
+func TestBuildReadPlan(t *testing.T) {
+	isolated := make([]ReadItem, 300)
+	for i := range isolated {
+		isolated[i] = wordItem(uint16(i*100), 1)
+	}
+	rangeAndPoints := []ReadItem{wordItem(0, 2000)}
+	for i := 0; i < 10; i++ {
+		rangeAndPoints = append(rangeAndPoints, wordItem(uint16(5000+i*100), 1))
+	}
+
+	tests := []struct {
+		name    string
+		items   []ReadItem
+		profile *Profile
+		options ReadPlanOptions
+		frames  int
+		ranges  int
+		multi   int
+		codes   []byte
+	}{
+		{
+			// D0 to D31 joined across a gap of 28 words, D100 beyond the gap
+			name:    "gap within MaxGap",
+			items:   []ReadItem{wordItem(0, 2), wordItem(30, 2), wordItem(100, 1)},
+			options: ReadPlanOptions{MaxGap: 32, NoMultipleRead: true},
+			frames:  2,
+			ranges:  2,
+		},
+		{
+			name:    "gap beyond MaxGap",
+			items:   []ReadItem{wordItem(0, 2), wordItem(30, 2), wordItem(100, 1)},
+			options: ReadPlanOptions{MaxGap: 27, NoMultipleRead: true},
+			frames:  3,
+			ranges:  3,
+		},
+		{
+			name:    "adjacent and overlapping items without gaps",
+			items:   []ReadItem{wordItem(0, 2), wordItem(2, 2), wordItem(3, 4)},
+			options: ReadPlanOptions{NoMultipleRead: true},
+			frames:  1,
+			ranges:  1,
+		},
+		{
+			// Five words in one Multiple Memory Area Read instead of two range reads
+			name:    "small blocks moved to multiple read",
+			items:   []ReadItem{wordItem(0, 2), wordItem(30, 2), wordItem(100, 1)},
+			options: ReadPlanOptions{MaxGap: 32},
+			frames:  1,
+			multi:   1,
+		},
+		{
+			// 300 points in two frames of at most 167 addresses
+			name:    "isolated points",
+			items:   isolated,
+			options: defaultReadPlanOptions,
+			frames:  2,
+			multi:   2,
+		},
+		{
+			// D0 to D1999 in three range frames and the ten points in one multiple read
+			name:    "range with isolated points",
+			items:   rangeAndPoints,
+			options: defaultReadPlanOptions,
+			frames:  4,
+			ranges:  1,
+			multi:   1,
+		},
+		{
+			name:    "profile without multiple read",
+			items:   isolated,
+			profile: profileWithout(CommandCodeMultipleMemoryAreaRead),
+			options: defaultReadPlanOptions,
+			frames:  300,
+			ranges:  300,
+		},
+		{
+			// D3.02 to D4.05 fold into D3 and D4, joining D5
+			name:    "bits folded into their words",
+			items:   []ReadItem{bitItem(MemoryAreaDMBit, 3, 2, 20), wordItem(5, 1)},
+			options: ReadPlanOptions{NoMultipleRead: true},
+			frames:  1,
+			ranges:  1,
+		},
+		{
+			// The bits of EM bank B and EM bank 10 are read from the words of their banks
+			name: "bits of extended memory banks",
+			items: []ReadItem{
+				bitItem(0x2b, 100, 0, 3),
+				bitItem(0xe0, 7, 15, 2),
+			},
+			options: ReadPlanOptions{NoMultipleRead: true},
+			frames:  2,
+			ranges:  2,
+			codes:   []byte{0x60, 0xab},
+		},
+		{
+			// Bits of areas without words are numbered from bit 0 of word 0: 0 to 2 and 16 are 13 bits apart
+			name: "bits of an area without words",
+			items: []ReadItem{
+				bitItem(MemoryAreaClockPulsesConditionFlagsBit, 0, 0, 3),
+				bitItem(MemoryAreaClockPulsesConditionFlagsBit, 1, 0, 1),
+			},
+			options: ReadPlanOptions{MaxGap: 13, NoMultipleRead: true},
+			frames:  1,
+			ranges:  1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profile := test.profile
+			if profile == nil {
+				profile = ProfileCSCJ
+			}
+			p, e := buildReadPlan(test.items, profile, test.options)
+			require.NoError(t, e)
+			assert.Equal(t, test.frames, p.Frames())
+			assert.Len(t, p.ranges, test.ranges)
+			assert.Len(t, p.frames, test.multi)
+			if test.codes != nil {
+				codes := make([]byte, len(p.blocks))
+				for i, block := range p.blocks {
+					codes[i] = block.code
+				}
+				assert.Equal(t, test.codes, codes)
+			}
+		})
+	}
+}
+
+func TestBuildReadPlanBlocks(t *testing.T) {
+	p, e := buildReadPlan([]ReadItem{
+		wordItem(5000, 1),
+		bitItem(MemoryAreaDMBit, 3, 2, 20),
+		wordItem(0, 2000),
+		bitItem(MemoryAreaWRBit, 10, 0, 1),
+		wordItem(9000, 1),
+	}, ProfileCSCJ, defaultReadPlanOptions)
+	require.NoError(t, e)
+
+	// The bits of D3 to D4 fall in the range of D0 to D1999, the W bit folds into W10, and the single words are read
+	// in memory area order with Multiple Memory Area Read
+	assert.Equal(t, []planSpan{
+		{code: MemoryAreaDMWord, size: 2, start: 0, end: 2000},
+		{code: MemoryAreaDMWord, size: 2, start: 5000, end: 5001},
+		{code: MemoryAreaDMWord, size: 2, start: 9000, end: 9001},
+		{code: MemoryAreaWRWord, size: 2, start: 10, end: 11},
+	}, p.blocks)
+	assert.Equal(t, []int{0}, p.ranges)
+	assert.Equal(t, [][]planElement{{{block: 1, offset: 0}, {block: 2, offset: 0}, {block: 3, offset: 0}}}, p.frames)
+	assert.Equal(t, 4, p.Frames())
+}
+
+func TestBuildReadPlanInvalidItem(t *testing.T) {
+	for _, item := range []ReadItem{
+		wordItem(0, 0),
+		{Address: IOAddress{MemoryArea: MemoryAreaCIOWordForced}, Count: 1},
+		{Address: IOAddress{MemoryArea: 0xff}, Count: 1},
+	} {
+		_, e := buildReadPlan([]ReadItem{item}, ProfileCSCJ, defaultReadPlanOptions)
+		assert.True(t, errors.Is(e, ErrInvalidReadItem), "item %v: error %v", item, e)
+	}
+}
+
+func TestReadItems(t *testing.T) {
+	for _, parallel := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d parallel frames", parallel), func(t *testing.T) {
+			_, c := newSimulator(t)
+			c.SetParallelFrames(parallel)
+
+			words := make([]uint16, 12000)
+			for i := range words {
+				words[i] = uint16(i*3 + 1)
+			}
+			require.NoError(t, c.WriteWords(MemoryAreaDMWord, 0, words))
+			require.NoError(t, c.WriteWords(MemoryAreaWRWord, 10, []uint16{0x8001}))
+
+			items := []ReadItem{wordItem(1500, 2000), bitItem(MemoryAreaDMBit, 3, 2, 20), bitItem(MemoryAreaWRBit, 10, 0, 16)}
+			for i := 0; i < 300; i++ {
+				items = append(items, wordItem(uint16(4000+i*25), 2))
+			}
+			items = append(items, wordItem(5, 1), wordItem(11000, 3))
+
+			s, e := c.ReadItems(items)
+			require.NoError(t, e)
+			for _, item := range items {
+				switch item.Address.MemoryArea {
+				case MemoryAreaDMWord:
+					read, e := s.Words(MemoryAreaDMWord, item.Address.Address, item.Count)
+					require.NoError(t, e)
+					assert.Equal(t, words[item.Address.Address:int(item.Address.Address)+item.Count], read)
+				case MemoryAreaDMBit:
+					read, e := s.Bits(MemoryAreaDMBit, 3, 2, 20)
+					require.NoError(t, e)
+					for i, bit := range read {
+						n := 3*16 + 2 + i
+						assert.Equal(t, words[n/16]&(1<<uint(n%16)) != 0, bit, "D%d.%02d", n/16, n%16)
+					}
+				}
+			}
+
+			bits, e := s.Bits(MemoryAreaWRBit, 10, 0, 16)
+			require.NoError(t, e)
+			assert.True(t, bits[0])
+			assert.True(t, bits[15])
+			assert.False(t, bits[1])
+
+			_, e = s.Words(MemoryAreaDMWord, 3999, 1)
+			assert.True(t, errors.Is(e, ErrNotRead))
+			_, e = s.Words(MemoryAreaDMWord, 3499, 2)
+			assert.True(t, errors.Is(e, ErrNotRead))
+			_, e = s.Words(MemoryAreaDMBit, 3, 1)
+			assert.True(t, errors.Is(e, ErrIncompatibleMemoryArea))
+		})
+	}
+}
+
+func TestPlanReadCache(t *testing.T) {
+	_, c := newSimulator(t)
+	items := []ReadItem{wordItem(0, 2), wordItem(30, 2), wordItem(100, 1)}
+
+	p, e := c.PlanRead(items)
+	require.NoError(t, e)
+	again, e := c.PlanRead(items)
+	require.NoError(t, e)
+	assert.Same(t, p, again)
+	assert.Equal(t, 1, p.Frames())
+
+	c.SetReadPlanOptions(ReadPlanOptions{MaxGap: 32, NoMultipleRead: true})
+	options, e := c.PlanRead(items)
+	require.NoError(t, e)
+	assert.NotSame(t, p, options)
+	assert.Equal(t, 2, options.Frames())
+
+	c.SetProfile(profileWithout(CommandCodeMultipleMemoryAreaRead))
+	c.SetReadPlanOptions(defaultReadPlanOptions)
+	profile, e := c.PlanRead(items)
+	require.NoError(t, e)
+	assert.NotSame(t, options, profile)
+	assert.Equal(t, 2, profile.Frames())
+}
+
+func TestReadTags(t *testing.T) {
+	_, c := newSimulator(t)
+	db, e := NewTagDatabase([]Tag{
+		{Name: "Speed", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 10}, DataType: DataTypeDint},
+		{Name: "Running", Address: IOAddress{MemoryArea: MemoryAreaDMBit, Address: 5, BitOffset: 1}, DataType: DataTypeBool},
+		{Name: "Setpoints", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 3000}, DataType: DataTypeUint,
+			ArrayLength: 3},
+	})
+	require.NoError(t, e)
+	c.SetTagDatabase(db)
+
+	require.NoError(t, c.WriteTag("Speed", int32(-70000)))
+	require.NoError(t, c.WriteTag("Running", true))
+	require.NoError(t, c.WriteTag("Setpoints", []uint16{1, 2, 3}))
+
+	values, e := c.ReadTags("Speed", "Running", "Setpoints")
+	require.NoError(t, e)
+	assert.Equal(t, map[string]interface{}{
+		"Speed":     int32(-70000),
+		"Running":   true,
+		"Setpoints": []uint16{1, 2, 3},
+	}, values)
+
+	_, e = c.ReadTags("Speed", "Missing")
+	assert.True(t, errors.Is(e, ErrUnknownTag))
+}
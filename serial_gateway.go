@@ -0,0 +1,24 @@
+package fins
+
+const (
+	// SerialBoardPort1 Unit address of port 1 of a serial communications board
+	SerialBoardPort1 byte = 0xe1
+
+	// SerialBoardPort2 Unit address of port 2 of a serial communications board
+	SerialBoardPort2 byte = 0xe2
+)
+
+// SerialUnitPort Returns the unit address of port 1 or 2 of the serial communications unit with the given unit
+// number, to which the serial gateway commands are sent
+func SerialUnitPort(unitNumber byte, port byte) byte {
+	return 0x80 + 4*unitNumber + port
+}
+
+// serialPort Returns the address of a serial port unit of the destination node
+func (c *Client) serialPort(unit byte) Address {
+	return Address{
+		Network: c.dst.Network,
+		Node:    c.dst.Node,
+		Unit:    unit,
+	}
+}
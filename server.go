@@ -1,9 +1,88 @@
 package fins
 
-// Server Omron FINS server
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Server Omron FINS server simulating a CPU unit
 type Server struct {
 	provider ServerProvider
 	addr     Address
+	mode     OperatingMode
+	memory   map[byte][]uint16
+	clock    time.Duration
+	cycle    CycleTime
+	messages [8]string
+	holder   *Address
+	errors   []uint16
+	errorLog []ErrorLogRecord
+	writeLog []WriteAccessLogRecord
+	files    map[string]*serverFile
+	forced   map[byte][]uint16
+
+	sync.Mutex
+}
+
+// serverWordMemoryAreas Number of words simulated in each word memory area
+var serverWordMemoryAreas = map[byte]int{
+	MemoryAreaCIOWord:        6144,
+	MemoryAreaWRWord:         512,
+	MemoryAreaHRWord:         512,
+	MemoryAreaARWord:         960,
+	MemoryAreaDMWord:         32768,
+	MemoryAreaTimerCounterPV: int(CounterAddressOffset) + 4096,
+	emWordCode(0):            32768, // extended memory bank 0, the current bank
+	emWordCode(1):            32768,
+	emWordCode(2):            32768,
+}
+
+// serverBitMemoryAreas Word memory area holding the bits of each bit memory area
+var serverBitMemoryAreas = map[byte]byte{
+	MemoryAreaCIOBit:       MemoryAreaCIOWord,
+	MemoryAreaWRBit:        MemoryAreaWRWord,
+	MemoryAreaHRBit:        MemoryAreaHRWord,
+	MemoryAreaARBit:        MemoryAreaARWord,
+	MemoryAreaDMBit:        MemoryAreaDMWord,
+	MemoryAreaEMCurrentBit: MemoryAreaEMCurrentWord,
+	emBitCode(0):           emWordCode(0),
+	emBitCode(1):           emWordCode(1),
+	emBitCode(2):           emWordCode(2),
+}
+
+// serverRestrictedCommands Operating modes in which a CPU unit accepts commands that alter its program or settings;
+// commands not listed are accepted in any operating mode
+var serverRestrictedCommands = map[uint16][]OperatingMode{
+	CommandCodeParameterAreaWrite:   {OperatingModeProgram},
+	CommandCodeParameterAreaClear:   {OperatingModeProgram},
+	CommandCodeProgramAreaWrite:     {OperatingModeProgram},
+	CommandCodeProgramAreaClear:     {OperatingModeProgram},
+	CommandCodeForcedSetReset:       {OperatingModeProgram, OperatingModeMonitor},
+	CommandCodeForcedSetResetCancel: {OperatingModeProgram, OperatingModeMonitor},
+}
+
+const (
+	// serverErrorLogCapacity Number of records kept in the simulated error log
+	serverErrorLogCapacity = 20
+
+	// serverWriteAccessLogCapacity Number of records kept in the simulated FINS write access log
+	serverWriteAccessLogCapacity = 64
+)
+
+// serverWriteCommands Commands recorded in the FINS write access log
+var serverWriteCommands = map[uint16]bool{
+	CommandCodeMemoryAreaWrite:        true,
+	CommandCodeMemoryAreaFill:         true,
+	CommandCodeMemoryAreaTransfer:     true,
+	CommandCodeMemoryAreaFileTransfer: true,
+	CommandCodeParameterAreaWrite:     true,
+	CommandCodeParameterAreaClear:     true,
+	CommandCodeProgramAreaWrite:       true,
+	CommandCodeProgramAreaClear:       true,
+	CommandCodeClockWrite:             true,
+	CommandCodeForcedSetReset:         true,
+	CommandCodeForcedSetResetCancel:   true,
 }
 
 // NewServer creates a new Omron FINS server
@@ -11,6 +90,15 @@ func NewServer(provider ServerProvider, addr Address) *Server {
 	s := new(Server)
 	s.provider = provider
 	s.addr = addr
+	s.mode = OperatingModeProgram
+	s.memory = make(map[byte][]uint16, len(serverWordMemoryAreas))
+	for memoryArea, size := range serverWordMemoryAreas {
+		s.memory[memoryArea] = make([]uint16, size)
+	}
+	s.memory[MemoryAreaEMCurrentWord] = s.memory[emWordCode(0)]
+	s.files = make(map[string]*serverFile)
+	s.forced = make(map[byte][]uint16)
+	s.provider.register(s.handle)
 
 	return s
 }
@@ -19,3 +107,622 @@ func NewServer(provider ServerProvider, addr Address) *Server {
 func (s *Server) Close() {
 	s.provider.close()
 }
+
+// OperatingMode Returns the simulated operating mode
+func (s *Server) OperatingMode() OperatingMode {
+	s.Lock()
+	defer s.Unlock()
+	return s.mode
+}
+
+// SetMessage Raises a simulated MSG instruction message, or clears it when text is empty
+func (s *Server) SetMessage(number int, text string) {
+	s.Lock()
+	defer s.Unlock()
+	if number >= 0 && number < len(s.messages) {
+		s.messages[number] = text
+	}
+}
+
+// RaiseError Simulates the CPU unit detecting an error, making it the current error and recording it in the error log
+func (s *Server) RaiseError(code uint16, details uint16) {
+	s.Lock()
+	defer s.Unlock()
+	s.errors = append(s.errors, code)
+	s.errorLog = append(s.errorLog, ErrorLogRecord{
+		Code:    code,
+		Details: details,
+		Time:    time.Now().Add(s.clock),
+	})
+	if len(s.errorLog) > serverErrorLogCapacity {
+		s.errorLog = s.errorLog[len(s.errorLog)-serverErrorLogCapacity:]
+	}
+}
+
+// SetCycleTime Sets the simulated cycle time statistics
+func (s *Server) SetCycleTime(t CycleTime) {
+	s.Lock()
+	defer s.Unlock()
+	s.cycle = t
+}
+
+func (s *Server) messageFlags() MessageFlags {
+	var flags MessageFlags
+	for n, text := range s.messages {
+		if text != "" {
+			flags |= 1 << uint(n)
+		}
+	}
+	return flags
+}
+
+func (s *Server) handle(header *Header, command *Payload) (*Header, *Payload) {
+	s.Lock()
+	endCode, data := s.execute(header, command)
+	if endCode == EndCodeNormalCompletion && serverWriteCommands[command.CommandCode] {
+		s.logWriteAccess(header.src, command.CommandCode)
+	}
+	s.Unlock()
+
+	if !header.IsResponseRequired() {
+		return nil, nil
+	}
+	response := &Payload{
+		CommandCode: command.CommandCode,
+		Data:        make([]byte, 2, 2+len(data)),
+	}
+	binary.BigEndian.PutUint16(response.Data, endCode)
+	response.Data = append(response.Data, data...)
+	return responseHeader(header), response
+}
+
+func (s *Server) execute(header *Header, command *Payload) (uint16, []byte) {
+	if endCode, data := s.checkAccessRight(header.src, command.CommandCode); endCode != EndCodeNormalCompletion {
+		return endCode, data
+	}
+	if endCode := s.checkOperatingMode(command.CommandCode); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+
+	switch command.CommandCode {
+	case CommandCodeMemoryAreaRead:
+		return s.memoryAreaRead(command.Data)
+	case CommandCodeMemoryAreaWrite:
+		return s.memoryAreaWrite(command.Data)
+	case CommandCodeMemoryAreaFill:
+		return s.memoryAreaFill(command.Data)
+	case CommandCodeMultipleMemoryAreaRead:
+		return s.multipleMemoryAreaRead(command.Data)
+	case CommandCodeRun:
+		return s.run(command.Data)
+	case CommandCodeStop:
+		return s.stop(command.Data)
+	case CommandCodeCPUUnitDataRead:
+		return s.cpuUnitDataRead(command.Data)
+	case CommandCodeCPUUnitStatusRead:
+		return s.cpuUnitStatusRead(command.Data)
+	case CommandCodeCycleTimeRead:
+		return s.cycleTimeRead(command.Data)
+	case CommandCodeClockRead:
+		return s.clockRead(command.Data)
+	case CommandCodeClockWrite:
+		return s.clockWrite(command.Data)
+	case CommandCodeMessageReadClear:
+		return s.messageReadClear(command.Data)
+	case CommandCodeAccessRightAcquire:
+		return s.accessRightAcquire(header.src, command.Data, false)
+	case CommandCodeAccessRightForcedAcquire:
+		return s.accessRightAcquire(header.src, command.Data, true)
+	case CommandCodeAccessRightRelease:
+		return s.accessRightRelease(header.src, command.Data)
+	case CommandCodeErrorClear:
+		return s.errorClear(command.Data)
+	case CommandCodeErrorLogRead:
+		return s.errorLogRead(command.Data)
+	case CommandCodeErrorLogClear:
+		return s.errorLogClear(command.Data)
+	case CommandCodeFINSWriteAccessLogRead:
+		return s.writeAccessLogRead(command.Data)
+	case CommandCodeFINSWriteAccessLogClear:
+		return s.writeAccessLogClear(command.Data)
+	case CommandCodeFileNameRead:
+		return s.fileNameRead(command.Data)
+	case CommandCodeSingleFileRead:
+		return s.singleFileRead(command.Data)
+	case CommandCodeSingleFileWrite:
+		return s.singleFileWrite(command.Data)
+	case CommandCodeFileMemoryFormat:
+		return s.fileMemoryFormat(command.Data)
+	case CommandCodeFileDelete:
+		return s.fileDelete(command.Data)
+	case CommandCodeFileCopy:
+		return s.fileCopy(command.Data)
+	case CommandCodeFileNameChange:
+		return s.fileNameChange(command.Data)
+	case CommandCodeDirectoryCreateDelete:
+		return s.directoryCreateDelete(command.Data)
+	case CommandCodeForcedSetReset:
+		return s.forcedSetReset(command.Data)
+	case CommandCodeForcedSetResetCancel:
+		return s.forcedSetResetCancel(command.Data)
+	case CommandCodeMemoryAreaFileTransfer:
+		return s.memoryAreaFileTransfer(command.Data)
+	}
+	return EndCodeUndefinedCommand, nil
+}
+
+// checkAccessRight Rejects commands that change the operating mode, program or settings of the CPU unit while
+// another node holds the access right
+func (s *Server) checkAccessRight(src Address, commandCode uint16) (uint16, []byte) {
+	if s.holder == nil || *s.holder == src {
+		return EndCodeNormalCompletion, nil
+	}
+	if _, ok := serverRestrictedCommands[commandCode]; ok ||
+		commandCode == CommandCodeRun || commandCode == CommandCodeStop {
+		return EndCodeAccessWriteErrorNoAccessRight, nil
+	}
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) checkOperatingMode(commandCode uint16) uint16 {
+	modes, ok := serverRestrictedCommands[commandCode]
+	if !ok {
+		return EndCodeNormalCompletion
+	}
+	for _, mode := range modes {
+		if mode == s.mode {
+			return EndCodeNormalCompletion
+		}
+	}
+
+	switch s.mode {
+	case OperatingModeRun:
+		return EndCodeNotExecutableInCurrentModeWrongPLCModeInRun
+	case OperatingModeMonitor:
+		return EndCodeNotExecutableInCurrentModeWrongPLCModeInMonitor
+	}
+	return EndCodeNotExecutableInCurrentModeWrongPLCModeInProgram
+}
+
+func (s *Server) run(data []byte) (uint16, []byte) {
+	if len(data) > 3 {
+		return EndCodeCommandTooLong, nil
+	}
+	if len(data) == 1 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) >= 2 && binary.BigEndian.Uint16(data[0:2]) != ProgramNumberCurrent {
+		return EndCodeProgramMissing, nil
+	}
+	mode := OperatingModeMonitor
+	if len(data) == 3 {
+		mode = OperatingMode(data[2])
+	}
+	if mode != OperatingModeMonitor && mode != OperatingModeRun {
+		return EndCodeParameterError, nil
+	}
+
+	s.mode = mode
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) stop(data []byte) (uint16, []byte) {
+	if len(data) > 2 {
+		return EndCodeCommandTooLong, nil
+	}
+	if len(data) == 1 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) == 2 && binary.BigEndian.Uint16(data[0:2]) != ProgramNumberCurrent {
+		return EndCodeProgramMissing, nil
+	}
+
+	s.mode = OperatingModeProgram
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) cpuUnitDataRead(data []byte) (uint16, []byte) {
+	if len(data) > 1 {
+		return EndCodeCommandTooLong, nil
+	}
+
+	d := &CPUUnitData{
+		Model:           "SIMULATOR",
+		Version:         "1.0",
+		ProgramAreaSize: 10 * 1024,
+		IOMSize: 2 * (len(s.memory[MemoryAreaCIOWord]) + len(s.memory[MemoryAreaWRWord]) +
+			len(s.memory[MemoryAreaHRWord]) + len(s.memory[MemoryAreaARWord])),
+		DMWords: len(s.memory[MemoryAreaDMWord]),
+	}
+	return EndCodeNormalCompletion, encodeCPUUnitData(d)
+}
+
+func (s *Server) cpuUnitStatusRead(data []byte) (uint16, []byte) {
+	if len(data) > 0 {
+		return EndCodeCommandTooLong, nil
+	}
+
+	status := &CPUUnitStatus{
+		Status:   CPUStatusRun,
+		Mode:     s.mode,
+		Messages: s.messageFlags(),
+	}
+	if len(s.errors) > 0 {
+		status.ErrorCode = s.errors[len(s.errors)-1]
+	}
+	if s.mode == OperatingModeProgram {
+		status.Status = CPUStatusStop
+	}
+	return EndCodeNormalCompletion, encodeCPUUnitStatus(status)
+}
+
+func (s *Server) cycleTimeRead(data []byte) (uint16, []byte) {
+	if len(data) < 1 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 1 {
+		return EndCodeCommandTooLong, nil
+	}
+
+	switch data[0] {
+	case cycleTimeInitialize:
+		s.cycle.Max = s.cycle.Average
+		s.cycle.Min = s.cycle.Average
+		return EndCodeNormalCompletion, nil
+	case cycleTimeRead:
+		response := make([]byte, 12)
+		binary.BigEndian.PutUint32(response[0:4], uint32(s.cycle.Average/cycleTimeUnit))
+		binary.BigEndian.PutUint32(response[4:8], uint32(s.cycle.Max/cycleTimeUnit))
+		binary.BigEndian.PutUint32(response[8:12], uint32(s.cycle.Min/cycleTimeUnit))
+		return EndCodeNormalCompletion, response
+	}
+	return EndCodeParameterError, nil
+}
+
+func (s *Server) clockRead(data []byte) (uint16, []byte) {
+	if len(data) > 0 {
+		return EndCodeCommandTooLong, nil
+	}
+
+	clock, e := encodeClock(time.Now().Add(s.clock))
+	if e != nil {
+		return EndCodeNoSuchDeviceClockMissing, nil
+	}
+	return EndCodeNormalCompletion, clock
+}
+
+func (s *Server) clockWrite(data []byte) (uint16, []byte) {
+	if len(data) < 5 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 7 {
+		return EndCodeCommandTooLong, nil
+	}
+	if len(data) == 5 {
+		data = append(data, 0x00)
+	}
+
+	clock, e := decodeClock(data, time.Local)
+	if e != nil {
+		return EndCodeParameterError, nil
+	}
+	s.clock = time.Until(clock.Time)
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) messageReadClear(data []byte) (uint16, []byte) {
+	if len(data) < 2 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 2 {
+		return EndCodeCommandTooLong, nil
+	}
+	parameter := binary.BigEndian.Uint16(data[0:2])
+	flags := MessageFlags(parameter & messageFlagsMask)
+
+	switch parameter & messageCommandMask {
+	case messageRead:
+		response := append([]byte(nil), data[0:2]...)
+		for n, text := range s.messages {
+			if flags.Has(n) {
+				response = append(response, encodeASCII(text, messageTextLength)...)
+			}
+		}
+		return EndCodeNormalCompletion, response
+	case messageClear:
+		for n := range s.messages {
+			if flags.Has(n) {
+				s.messages[n] = ""
+			}
+		}
+		return EndCodeNormalCompletion, nil
+	case messageFALRead:
+		response := []byte{0x00, 0x00}
+		response = append(response, encodeASCII("", falMessageLength)...)
+		return EndCodeNormalCompletion, response
+	}
+	return EndCodeParameterError, nil
+}
+
+func (s *Server) accessRightAcquire(src Address, data []byte, forced bool) (uint16, []byte) {
+	if len(data) < 2 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 2 {
+		return EndCodeCommandTooLong, nil
+	}
+	if binary.BigEndian.Uint16(data[0:2]) != ProgramNumberCurrent {
+		return EndCodeProgramMissing, nil
+	}
+	if !forced && s.holder != nil && *s.holder != src {
+		return EndCodeAccessWriteErrorNoAccessRight, []byte{s.holder.Network, s.holder.Node, s.holder.Unit}
+	}
+
+	s.holder = &src
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) accessRightRelease(src Address, data []byte) (uint16, []byte) {
+	if len(data) < 2 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 2 {
+		return EndCodeCommandTooLong, nil
+	}
+	if binary.BigEndian.Uint16(data[0:2]) != ProgramNumberCurrent {
+		return EndCodeProgramMissing, nil
+	}
+
+	if s.holder != nil && *s.holder == src {
+		s.holder = nil
+	}
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) errorClear(data []byte) (uint16, []byte) {
+	if len(data) < 2 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 2 {
+		return EndCodeCommandTooLong, nil
+	}
+
+	code := binary.BigEndian.Uint16(data[0:2])
+	switch {
+	case code == ErrorClearAll:
+		s.errors = nil
+	case code == ErrorClearCurrent && len(s.errors) > 0:
+		s.errors = s.errors[:len(s.errors)-1]
+	default:
+		remaining := s.errors[:0]
+		for _, e := range s.errors {
+			if e != code {
+				remaining = append(remaining, e)
+			}
+		}
+		s.errors = remaining
+	}
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) errorLogRead(data []byte) (uint16, []byte) {
+	return serverLogRead(data, serverErrorLogCapacity, len(s.errorLog), func(i int) []byte {
+		return encodeErrorLogRecord(&s.errorLog[i])
+	})
+}
+
+func (s *Server) errorLogClear(data []byte) (uint16, []byte) {
+	if len(data) > 0 {
+		return EndCodeCommandTooLong, nil
+	}
+
+	s.errorLog = nil
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) logWriteAccess(src Address, commandCode uint16) {
+	s.writeLog = append(s.writeLog, WriteAccessLogRecord{
+		Source:      src,
+		CommandCode: commandCode,
+		Time:        time.Now().Add(s.clock),
+	})
+	if len(s.writeLog) > serverWriteAccessLogCapacity {
+		s.writeLog = s.writeLog[len(s.writeLog)-serverWriteAccessLogCapacity:]
+	}
+}
+
+func (s *Server) writeAccessLogRead(data []byte) (uint16, []byte) {
+	return serverLogRead(data, serverWriteAccessLogCapacity, len(s.writeLog), func(i int) []byte {
+		return encodeWriteAccessLogRecord(&s.writeLog[i])
+	})
+}
+
+// serverLogRead Reads the records of a log of the given capacity holding stored records, each encoded by encode
+func serverLogRead(data []byte, capacity int, stored int, encode func(i int) []byte) (uint16, []byte) {
+	if len(data) < 4 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 4 {
+		return EndCodeCommandTooLong, nil
+	}
+	first := int(binary.BigEndian.Uint16(data[0:2]))
+	count := int(binary.BigEndian.Uint16(data[2:4]))
+	if first >= capacity || count == 0 || count > logReadMax {
+		return EndCodeParameterError, nil
+	}
+	if first+count > stored {
+		count = stored - first
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	response := make([]byte, logHeaderLength)
+	binary.BigEndian.PutUint16(response[0:2], uint16(capacity))
+	binary.BigEndian.PutUint16(response[2:4], uint16(stored))
+	binary.BigEndian.PutUint16(response[4:6], uint16(count))
+	for i := 0; i < count; i++ {
+		response = append(response, encode(first+i)...)
+	}
+	return EndCodeNormalCompletion, response
+}
+
+func (s *Server) writeAccessLogClear(data []byte) (uint16, []byte) {
+	if len(data) > 0 {
+		return EndCodeCommandTooLong, nil
+	}
+
+	s.writeLog = nil
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) memoryAreaRead(data []byte) (uint16, []byte) {
+	if len(data) < 6 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 6 {
+		return EndCodeCommandTooLong, nil
+	}
+	ioAddr := decodeIOAddress(data[0:4])
+	itemCount := int(binary.BigEndian.Uint16(data[4:6]))
+
+	if endCode, bytes, ok := s.forcedStatusRead(ioAddr, itemCount); ok {
+		return endCode, bytes
+	}
+
+	if words, ok := s.memory[ioAddr.MemoryArea]; ok {
+		if endCode := checkServerWordRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+			return endCode, nil
+		}
+		bytes := make([]byte, 2*itemCount)
+		for i := 0; i < itemCount; i++ {
+			binary.BigEndian.PutUint16(bytes[i*2:i*2+2], words[int(ioAddr.Address)+i])
+		}
+		return EndCodeNormalCompletion, bytes
+	}
+
+	if wordArea, ok := serverBitMemoryAreas[ioAddr.MemoryArea]; ok {
+		words := s.memory[wordArea]
+		if endCode := checkServerBitRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+			return endCode, nil
+		}
+		bytes := make([]byte, itemCount)
+		start := int(ioAddr.Address)*16 + int(ioAddr.BitOffset)
+		for i := 0; i < itemCount; i++ {
+			n := start + i
+			bytes[i] = byte(words[n/16]>>(n%16)) & 0x01
+		}
+		return EndCodeNormalCompletion, bytes
+	}
+
+	return EndCodeAreaClassificationMissing, nil
+}
+
+// multipleMemoryAreaRead Reads one element at each address, preceded by the memory area code of the address
+func (s *Server) multipleMemoryAreaRead(data []byte) (uint16, []byte) {
+	if len(data) < 4 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data)%4 != 0 {
+		return EndCodeCommandTooLong, nil
+	}
+	bytes := make([]byte, 0, 3*len(data)/4)
+	for i := 0; i < len(data); i += 4 {
+		endCode, element := s.memoryAreaRead(append(append([]byte{}, data[i:i+4]...), 0x00, 0x01))
+		if endCode != EndCodeNormalCompletion {
+			return endCode, nil
+		}
+		bytes = append(bytes, data[i])
+		bytes = append(bytes, element...)
+	}
+	return EndCodeNormalCompletion, bytes
+}
+
+func (s *Server) memoryAreaWrite(data []byte) (uint16, []byte) {
+	if len(data) < 6 {
+		return EndCodeCommandTooShort, nil
+	}
+	ioAddr := decodeIOAddress(data[0:4])
+	itemCount := int(binary.BigEndian.Uint16(data[4:6]))
+	bytes := data[6:]
+
+	if words, ok := s.memory[ioAddr.MemoryArea]; ok {
+		if len(bytes) != 2*itemCount {
+			return EndCodeElementsDataDontMatch, nil
+		}
+		if endCode := checkServerWordRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+			return endCode, nil
+		}
+		for i := 0; i < itemCount; i++ {
+			s.writeWord(ioAddr.MemoryArea, int(ioAddr.Address)+i, binary.BigEndian.Uint16(bytes[i*2:i*2+2]))
+		}
+		return EndCodeNormalCompletion, nil
+	}
+
+	if wordArea, ok := serverBitMemoryAreas[ioAddr.MemoryArea]; ok {
+		words := s.memory[wordArea]
+		if len(bytes) != itemCount {
+			return EndCodeElementsDataDontMatch, nil
+		}
+		if endCode := checkServerBitRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+			return endCode, nil
+		}
+		for _, b := range bytes {
+			if b > 0x01 {
+				return EndCodeParameterError, nil
+			}
+		}
+		start := int(ioAddr.Address)*16 + int(ioAddr.BitOffset)
+		for i, b := range bytes {
+			n := start + i
+			s.writeWord(wordArea, n/16, words[n/16]&^(1<<(n%16))|uint16(b)<<(n%16))
+		}
+		return EndCodeNormalCompletion, nil
+	}
+
+	return EndCodeAreaClassificationMissing, nil
+}
+
+func (s *Server) memoryAreaFill(data []byte) (uint16, []byte) {
+	if len(data) < 8 {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 8 {
+		return EndCodeCommandTooLong, nil
+	}
+	ioAddr := decodeIOAddress(data[0:4])
+	itemCount := int(binary.BigEndian.Uint16(data[4:6]))
+	value := binary.BigEndian.Uint16(data[6:8])
+
+	words, ok := s.memory[ioAddr.MemoryArea]
+	if !ok {
+		return EndCodeAreaClassificationMissing, nil
+	}
+	if endCode := checkServerWordRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	for i := 0; i < itemCount; i++ {
+		s.writeWord(ioAddr.MemoryArea, int(ioAddr.Address)+i, value)
+	}
+	return EndCodeNormalCompletion, nil
+}
+
+func checkServerWordRange(words []uint16, ioAddr IOAddress, itemCount int) uint16 {
+	if ioAddr.BitOffset != 0 {
+		return EndCodeAddressRangeError
+	}
+	if int(ioAddr.Address)+itemCount > len(words) {
+		return EndCodeAddressRangeExceeded
+	}
+	return EndCodeNormalCompletion
+}
+
+func checkServerBitRange(words []uint16, ioAddr IOAddress, itemCount int) uint16 {
+	if ioAddr.BitOffset > 15 {
+		return EndCodeAddressRangeError
+	}
+	if int(ioAddr.Address)*16+int(ioAddr.BitOffset)+itemCount > len(words)*16 {
+		return EndCodeAddressRangeExceeded
+	}
+	return EndCodeNormalCompletion
+}
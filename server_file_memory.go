@@ -0,0 +1,414 @@
+package fins
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"time"
+)
+
+// serverFileMemoryCapacity Capacity in bytes of the simulated memory card
+const serverFileMemoryCapacity = 1 << 20
+
+// serverFile A file or directory of the simulated memory card
+type serverFile struct {
+	data    []byte
+	dir     bool
+	modTime time.Time
+}
+
+// serverFilePath Joins a directory path and file name as the key of the simulated memory card
+func serverFilePath(dir string, name string) string {
+	return strings.TrimSuffix(strings.ToUpper(dir), `\`) + `\` + strings.ToUpper(name)
+}
+
+// decodeServerFilePath Decodes a directory length and path, returning the path and the remaining data
+func decodeServerFilePath(data []byte) (string, []byte, bool) {
+	if len(data) < 2 {
+		return "", nil, false
+	}
+	n := int(binary.BigEndian.Uint16(data[0:2]))
+	if len(data) < 2+n {
+		return "", nil, false
+	}
+	return string(data[2 : 2+n]), data[2+n:], true
+}
+
+func (s *Server) checkServerDisk(data []byte) uint16 {
+	if len(data) < 2 {
+		return EndCodeCommandTooShort
+	}
+	if binary.BigEndian.Uint16(data[0:2]) != DiskMemoryCard {
+		return EndCodeNoSuchDeviceFileDeviceMissing
+	}
+	return EndCodeNormalCompletion
+}
+
+// checkServerDirectory Reports whether the directory exists on the simulated memory card
+func (s *Server) checkServerDirectory(dir string) bool {
+	if dir == `\` {
+		return true
+	}
+	f, ok := s.files[strings.ToUpper(dir)]
+	return ok && f.dir
+}
+
+func (s *Server) fileMemoryUsed() int64 {
+	var used int64
+	for _, f := range s.files {
+		used += int64(len(f.data))
+	}
+	return used
+}
+
+func (s *Server) fileNameRead(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	if len(data) < 6 {
+		return EndCodeCommandTooShort, nil
+	}
+	position := int(binary.BigEndian.Uint16(data[2:4]))
+	count := int(binary.BigEndian.Uint16(data[4:6]))
+	dir, _, ok := decodeServerFilePath(data[6:])
+	if !ok {
+		return EndCodeCommandTooShort, nil
+	}
+	if !s.checkServerDirectory(dir) {
+		return EndCodeReadNotPossibleFileMissing, nil
+	}
+
+	prefix := serverFilePath(dir, "")
+	names := make([]string, 0)
+	for key := range s.files {
+		if strings.HasPrefix(key, prefix) && !strings.Contains(key[len(prefix):], `\`) {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	response := make([]byte, diskDataLength+2)
+	copy(response[0:12], encodeASCII("SIMULATOR", 12))
+	binary.BigEndian.PutUint32(response[16:20], serverFileMemoryCapacity)
+	binary.BigEndian.PutUint32(response[20:24], uint32(serverFileMemoryCapacity-s.fileMemoryUsed()))
+	binary.BigEndian.PutUint16(response[24:26], uint16(len(names)))
+
+	n := 0
+	for i := position; i < len(names) && n < count; i++ {
+		f := s.files[names[i]]
+		response = append(response, encodeFileData(&fileMemoryInfo{
+			name:    names[i][len(prefix):],
+			size:    int64(len(f.data)),
+			modTime: f.modTime,
+			dir:     f.dir,
+		})...)
+		n++
+	}
+	flags := uint16(n)
+	if position+n >= len(names) {
+		flags |= fileLastFlag
+	}
+	binary.BigEndian.PutUint16(response[diskDataLength:diskDataLength+2], flags)
+	return EndCodeNormalCompletion, response
+}
+
+func (s *Server) singleFileRead(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	if len(data) < 2+fileNameLength+6 {
+		return EndCodeCommandTooShort, nil
+	}
+	name := decodeFileName(data[2 : 2+fileNameLength])
+	position := int(binary.BigEndian.Uint32(data[2+fileNameLength : 6+fileNameLength]))
+	length := int(binary.BigEndian.Uint16(data[6+fileNameLength : 8+fileNameLength]))
+	dir, _, ok := decodeServerFilePath(data[8+fileNameLength:])
+	if !ok {
+		return EndCodeCommandTooShort, nil
+	}
+	f, ok := s.files[serverFilePath(dir, name)]
+	if !ok || f.dir {
+		return EndCodeReadNotPossibleFileMissing, nil
+	}
+	if position > len(f.data) {
+		return EndCodeParameterError, nil
+	}
+	if length > fileTransferMax {
+		length = fileTransferMax
+	}
+	if position+length > len(f.data) {
+		length = len(f.data) - position
+	}
+
+	response := make([]byte, 10, 10+length)
+	binary.BigEndian.PutUint32(response[0:4], uint32(len(f.data)))
+	binary.BigEndian.PutUint32(response[4:8], uint32(position))
+	flags := uint16(length)
+	if position+length >= len(f.data) {
+		flags |= fileLastFlag
+	}
+	binary.BigEndian.PutUint16(response[8:10], flags)
+	return EndCodeNormalCompletion, append(response, f.data[position:position+length]...)
+}
+
+func (s *Server) singleFileWrite(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	if len(data) < 4+fileNameLength+6 {
+		return EndCodeCommandTooShort, nil
+	}
+	parameter := binary.BigEndian.Uint16(data[2:4])
+	name := decodeFileName(data[4 : 4+fileNameLength])
+	position := int(binary.BigEndian.Uint32(data[4+fileNameLength : 8+fileNameLength]))
+	length := int(binary.BigEndian.Uint16(data[8+fileNameLength : 10+fileNameLength]))
+	dir, content, ok := decodeServerFilePath(data[10+fileNameLength:])
+	if !ok || len(content) < length {
+		return EndCodeCommandTooShort, nil
+	}
+	if !s.checkServerDirectory(dir) {
+		return EndCodeWriteNotPossibleFileMissing, nil
+	}
+	content = content[:length]
+
+	key := serverFilePath(dir, name)
+	f, exists := s.files[key]
+	previous := 0
+	if exists {
+		previous = len(f.data)
+	}
+	if exists && f.dir {
+		return EndCodeWriteNotPossibleFileNameAlreadyExists, nil
+	}
+	switch parameter {
+	case fileWriteNew:
+		if exists {
+			return EndCodeWriteNotPossibleFileNameAlreadyExists, nil
+		}
+		f = &serverFile{data: append([]byte{}, content...)}
+	case fileWriteReplace:
+		f = &serverFile{data: append([]byte{}, content...)}
+	case fileWriteAppend:
+		if !exists {
+			return EndCodeWriteNotPossibleFileMissing, nil
+		}
+		f.data = append(f.data, content...)
+	case fileWriteOverwrite:
+		if !exists {
+			return EndCodeWriteNotPossibleFileMissing, nil
+		}
+		if position > len(f.data) {
+			return EndCodeParameterError, nil
+		}
+		if end := position + len(content); end > len(f.data) {
+			f.data = append(f.data, make([]byte, end-len(f.data))...)
+		}
+		copy(f.data[position:], content)
+	default:
+		return EndCodeParameterError, nil
+	}
+	if s.fileMemoryUsed()-int64(previous)+int64(len(f.data)) > serverFileMemoryCapacity {
+		return EndCodeWriteNotPossibleCannotRegister, nil
+	}
+	f.modTime = time.Now().Add(s.clock)
+	s.files[key] = f
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) fileMemoryFormat(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	s.files = make(map[string]*serverFile)
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) fileDelete(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	if len(data) < 4 {
+		return EndCodeCommandTooShort, nil
+	}
+	count := int(binary.BigEndian.Uint16(data[2:4]))
+	dir, names, ok := decodeServerFilePath(data[4:])
+	if !ok || len(names) < count*fileNameLength {
+		return EndCodeCommandTooShort, nil
+	}
+
+	deleted := 0
+	for i := 0; i < count; i++ {
+		key := serverFilePath(dir, decodeFileName(names[i*fileNameLength:(i+1)*fileNameLength]))
+		if f, ok := s.files[key]; ok && !f.dir {
+			delete(s.files, key)
+			deleted++
+		}
+	}
+	response := make([]byte, 2)
+	binary.BigEndian.PutUint16(response, uint16(deleted))
+	return EndCodeNormalCompletion, response
+}
+
+func (s *Server) fileCopy(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	dir, rest, ok := decodeServerFilePath(data[2:])
+	if !ok || len(rest) < fileNameLength {
+		return EndCodeCommandTooShort, nil
+	}
+	name := decodeFileName(rest[:fileNameLength])
+	rest = rest[fileNameLength:]
+	if endCode := s.checkServerDisk(rest); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	dstDir, rest, ok := decodeServerFilePath(rest[2:])
+	if !ok || len(rest) < fileNameLength {
+		return EndCodeCommandTooShort, nil
+	}
+	dstName := decodeFileName(rest[:fileNameLength])
+
+	f, ok := s.files[serverFilePath(dir, name)]
+	if !ok || f.dir {
+		return EndCodeReadNotPossibleFileMissing, nil
+	}
+	if !s.checkServerDirectory(dstDir) {
+		return EndCodeWriteNotPossibleFileMissing, nil
+	}
+	dst := serverFilePath(dstDir, dstName)
+	if _, exists := s.files[dst]; exists {
+		return EndCodeWriteNotPossibleFileNameAlreadyExists, nil
+	}
+	s.files[dst] = &serverFile{data: append([]byte{}, f.data...), modTime: f.modTime}
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) fileNameChange(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	dir, names, ok := decodeServerFilePath(data[2:])
+	if !ok || len(names) < 2*fileNameLength {
+		return EndCodeCommandTooShort, nil
+	}
+	oldKey := serverFilePath(dir, decodeFileName(names[:fileNameLength]))
+	newKey := serverFilePath(dir, decodeFileName(names[fileNameLength:2*fileNameLength]))
+
+	f, ok := s.files[oldKey]
+	if !ok {
+		return EndCodeWriteNotPossibleFileMissing, nil
+	}
+	if _, exists := s.files[newKey]; exists {
+		return EndCodeWriteNotPossibleFileNameAlreadyExists, nil
+	}
+	delete(s.files, oldKey)
+	s.files[newKey] = f
+	if f.dir {
+		for key, child := range s.files {
+			if strings.HasPrefix(key, oldKey+`\`) {
+				delete(s.files, key)
+				s.files[newKey+key[len(oldKey):]] = child
+			}
+		}
+	}
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) directoryCreateDelete(data []byte) (uint16, []byte) {
+	if endCode := s.checkServerDisk(data); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	if len(data) < 4 {
+		return EndCodeCommandTooShort, nil
+	}
+	parameter := binary.BigEndian.Uint16(data[2:4])
+	dir, name, ok := decodeServerFilePath(data[4:])
+	if !ok || len(name) < fileNameLength {
+		return EndCodeCommandTooShort, nil
+	}
+	key := serverFilePath(dir, decodeFileName(name[:fileNameLength]))
+
+	switch parameter {
+	case directoryCreate:
+		if !s.checkServerDirectory(dir) {
+			return EndCodeWriteNotPossibleFileMissing, nil
+		}
+		if _, exists := s.files[key]; exists {
+			return EndCodeWriteNotPossibleFileNameAlreadyExists, nil
+		}
+		s.files[key] = &serverFile{dir: true, modTime: time.Now().Add(s.clock)}
+	case directoryDelete:
+		if f, ok := s.files[key]; !ok || !f.dir {
+			return EndCodeWriteNotPossibleFileMissing, nil
+		}
+		for other := range s.files {
+			if strings.HasPrefix(other, key+`\`) {
+				return EndCodeWriteNotPossibleCannotChange, nil
+			}
+		}
+		delete(s.files, key)
+	default:
+		return EndCodeParameterError, nil
+	}
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) memoryAreaFileTransfer(data []byte) (uint16, []byte) {
+	if len(data) < 10 {
+		return EndCodeCommandTooShort, nil
+	}
+	direction := FileTransfer(binary.BigEndian.Uint16(data[0:2]))
+	ioAddr := decodeIOAddress(data[2:6])
+	itemCount := int(binary.BigEndian.Uint16(data[6:8]))
+	if endCode := s.checkServerDisk(data[8:]); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	dir, name, ok := decodeServerFilePath(data[10:])
+	if !ok || len(name) < fileNameLength {
+		return EndCodeCommandTooShort, nil
+	}
+	key := serverFilePath(dir, decodeFileName(name[:fileNameLength]))
+
+	words, ok := s.memory[ioAddr.MemoryArea]
+	if !ok {
+		return EndCodeAreaClassificationMissing, nil
+	}
+	if endCode := checkServerWordRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+		return endCode, nil
+	}
+	area := words[ioAddr.Address : int(ioAddr.Address)+itemCount]
+
+	switch direction {
+	case FileTransferToFile:
+		if !s.checkServerDirectory(dir) {
+			return EndCodeWriteNotPossibleFileMissing, nil
+		}
+		f := &serverFile{data: make([]byte, 2*itemCount), modTime: time.Now().Add(s.clock)}
+		for i, word := range area {
+			binary.BigEndian.PutUint16(f.data[i*2:i*2+2], word)
+		}
+		s.files[key] = f
+	case FileTransferFromFile, FileTransferCompare:
+		f, ok := s.files[key]
+		if !ok || f.dir {
+			return EndCodeReadNotPossibleFileMissing, nil
+		}
+		if len(f.data)/2 < itemCount {
+			itemCount = len(f.data) / 2
+		}
+		for i := 0; i < itemCount; i++ {
+			word := binary.BigEndian.Uint16(f.data[i*2 : i*2+2])
+			if direction == FileTransferFromFile {
+				area[i] = word
+			} else if area[i] != word {
+				return EndCodeReadNotPossibleDataMismatch, nil
+			}
+		}
+	default:
+		return EndCodeParameterError, nil
+	}
+
+	response := make([]byte, 2)
+	binary.BigEndian.PutUint16(response, uint16(itemCount))
+	return EndCodeNormalCompletion, response
+}
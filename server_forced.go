@@ -0,0 +1,128 @@
+package fins
+
+import "encoding/binary"
+
+// serverForcedMemoryAreas Word memory areas whose bits can be forced, with the memory areas reading their bits and
+// words with forced status
+var serverForcedMemoryAreas = map[byte]struct {
+	bit  byte
+	word byte
+}{
+	MemoryAreaCIOWord: {MemoryAreaCIOBitForced, MemoryAreaCIOWordForced},
+	MemoryAreaWRWord:  {MemoryAreaWRBitForced, MemoryAreaWRWordForced},
+	MemoryAreaHRWord:  {MemoryAreaHRBitForced, MemoryAreaHRWordForced},
+}
+
+// forcedMask Returns the forced bits of a word
+func (s *Server) forcedMask(memoryArea byte, index int) uint16 {
+	if mask, ok := s.forced[memoryArea]; ok {
+		return mask[index]
+	}
+	return 0
+}
+
+// writeWord Writes a word, leaving its forced bits unchanged
+func (s *Server) writeWord(memoryArea byte, index int, value uint16) {
+	words := s.memory[memoryArea]
+	mask := s.forcedMask(memoryArea, index)
+	words[index] = words[index]&mask | value&^mask
+}
+
+func (s *Server) forcedSetReset(data []byte) (uint16, []byte) {
+	if len(data) < 2 {
+		return EndCodeCommandTooShort, nil
+	}
+	count := int(binary.BigEndian.Uint16(data[0:2]))
+	if len(data) < 2+count*forcedBitLength {
+		return EndCodeCommandTooShort, nil
+	}
+	if len(data) > 2+count*forcedBitLength {
+		return EndCodeCommandTooLong, nil
+	}
+
+	bits := make([]ForcedBit, count)
+	for i := range bits {
+		spec := data[2+i*forcedBitLength:]
+		ioAddr := decodeIOAddress(spec[2:6])
+		bits[i] = ForcedBit{
+			Action:     ForceAction(binary.BigEndian.Uint16(spec[0:2])),
+			MemoryArea: ioAddr.MemoryArea,
+			Address:    ioAddr.Address,
+			BitOffset:  ioAddr.BitOffset,
+		}
+		wordArea, ok := serverBitMemoryAreas[bits[i].MemoryArea]
+		if _, forceable := serverForcedMemoryAreas[wordArea]; !ok || !forceable {
+			return EndCodeAreaClassificationMissing, nil
+		}
+		if endCode := checkServerBitRange(s.memory[wordArea], ioAddr, 1); endCode != EndCodeNormalCompletion {
+			return endCode, nil
+		}
+		switch bits[i].Action {
+		case ForceReset, ForceSet, ForceReleaseOff, ForceReleaseOn, ForceRelease:
+		default:
+			return EndCodeParameterError, nil
+		}
+	}
+
+	for _, bit := range bits {
+		wordArea := serverBitMemoryAreas[bit.MemoryArea]
+		words := s.memory[wordArea]
+		if _, ok := s.forced[wordArea]; !ok {
+			s.forced[wordArea] = make([]uint16, len(words))
+		}
+		mask := uint16(1) << bit.BitOffset
+		switch bit.Action {
+		case ForceSet, ForceReleaseOn:
+			words[bit.Address] |= mask
+		case ForceReset, ForceReleaseOff:
+			words[bit.Address] &^= mask
+		}
+		if bit.Action == ForceSet || bit.Action == ForceReset {
+			s.forced[wordArea][bit.Address] |= mask
+		} else {
+			s.forced[wordArea][bit.Address] &^= mask
+		}
+	}
+	return EndCodeNormalCompletion, nil
+}
+
+func (s *Server) forcedSetResetCancel(data []byte) (uint16, []byte) {
+	if len(data) > 0 {
+		return EndCodeCommandTooLong, nil
+	}
+	s.forced = make(map[byte][]uint16)
+	return EndCodeNormalCompletion, nil
+}
+
+// forcedStatusRead Reads bits or words with their forced status, reporting false if the memory area is not one
+// reading forced status
+func (s *Server) forcedStatusRead(ioAddr IOAddress, itemCount int) (uint16, []byte, bool) {
+	for wordArea, areas := range serverForcedMemoryAreas {
+		words := s.memory[wordArea]
+		switch ioAddr.MemoryArea {
+		case areas.word:
+			if endCode := checkServerWordRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+				return endCode, nil, true
+			}
+			bytes := make([]byte, 4*itemCount)
+			for i := 0; i < itemCount; i++ {
+				n := int(ioAddr.Address) + i
+				binary.BigEndian.PutUint16(bytes[i*4:i*4+2], words[n])
+				binary.BigEndian.PutUint16(bytes[i*4+2:i*4+4], s.forcedMask(wordArea, n))
+			}
+			return EndCodeNormalCompletion, bytes, true
+		case areas.bit:
+			if endCode := checkServerBitRange(words, ioAddr, itemCount); endCode != EndCodeNormalCompletion {
+				return endCode, nil, true
+			}
+			bytes := make([]byte, itemCount)
+			start := int(ioAddr.Address)*16 + int(ioAddr.BitOffset)
+			for i := 0; i < itemCount; i++ {
+				n := start + i
+				bytes[i] = byte(words[n/16]>>(n%16))&0x01 | byte(s.forcedMask(wordArea, n/16)>>(n%16))&0x01<<1
+			}
+			return EndCodeNormalCompletion, bytes, true
+		}
+	}
+	return EndCodeNormalCompletion, nil, false
+}
@@ -2,8 +2,8 @@ package fins
 
 // ServerProvider is the interface implements underlying methods.
 type ServerProvider interface {
-	// Send command
-	// register(handler func(header *Header, payload *Payload) (*Header, *Payload)) error
+	// Register the handler for received commands; a nil response header means no response is sent
+	register(handler func(header *Header, payload *Payload) (*Header, *Payload)) error
 
 	// Close connection
 	close() error
@@ -0,0 +1,49 @@
+package fins
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newSimulator Starts a simulated CPU unit on a free local port and returns it with a client connected to it
+func newSimulator(t *testing.T) (*Server, *Client) {
+	t.Helper()
+	sp, e := NewUDPServerProvider("127.0.0.1:0")
+	require.NoError(t, e)
+	s := NewServer(sp, Address{Network: 0, Node: 10, Unit: 0})
+	t.Cleanup(s.Close)
+	return s, newSimulatorClient(t, s, 2)
+}
+
+// newSimulatorClient Returns another client of a simulated CPU unit, sending from the given node
+func newSimulatorClient(t *testing.T, s *Server, node byte) *Client {
+	t.Helper()
+	cp, e := NewUDPClientProvider(s.provider.(*UDPServerProvider).conn.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, e)
+	return NewClient(cp, Address{Network: 0, Node: 10, Unit: 0}, Address{Network: 0, Node: node, Unit: 0})
+}
+
+// testProvider A client provider answering each command with the response of a function, for commands the simulator
+// does not serve
+type testProvider struct {
+	header  *Header
+	command *Payload
+	respond func(command *Payload) *Response
+}
+
+func (p *testProvider) close() error {
+	return nil
+}
+
+func (p *testProvider) sendCommand(header *Header, command *Payload) (*Response, error) {
+	p.header, p.command = header, command
+	return p.respond(command), nil
+}
+
+// newTestClient Returns a client of a test provider answering with respond
+func newTestClient(respond func(command *Payload) *Response) (*testProvider, *Client) {
+	p := &testProvider{respond: respond}
+	return p, NewClient(p, Address{Network: 0, Node: 10, Unit: 0}, Address{Network: 0, Node: 2, Unit: 0})
+}
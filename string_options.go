@@ -0,0 +1,165 @@
+package fins
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+// StringByteOrder Order of the two characters of each word of a string
+type StringByteOrder byte
+
+const (
+	// StringByteOrderHighFirst The first character of each word in its upper byte, as CX-Programmer stores strings
+	StringByteOrderHighFirst StringByteOrder = iota
+
+	// StringByteOrderLowFirst The first character of each word in its lower byte
+	StringByteOrderLowFirst
+)
+
+// StringPadding Byte filling the words of a string after its last character
+type StringPadding byte
+
+const (
+	// StringPaddingNUL Strings padded with NUL, and read up to the first NUL
+	StringPaddingNUL StringPadding = iota
+
+	// StringPaddingSpace Strings padded with spaces, and read up to the first NUL without trailing spaces
+	StringPaddingSpace
+)
+
+// StringLayout Layout of a string in its words
+type StringLayout byte
+
+const (
+	// StringLayoutFixed Characters from the first word on
+	StringLayoutFixed StringLayout = iota
+
+	// StringLayoutLengthPrefixed Number of bytes in the first word, followed by the characters
+	StringLayoutLengthPrefixed
+)
+
+// StringEncoding Character encoding of strings
+type StringEncoding byte
+
+const (
+	// StringEncodingUTF8 UTF-8, which includes ASCII
+	StringEncodingUTF8 StringEncoding = iota
+
+	// StringEncodingShiftJIS Shift-JIS, as stored by Japanese HMIs and programs
+	StringEncodingShiftJIS
+)
+
+// StringOptions Layout and encoding of strings in the PLC memory, the zero value being NUL padded UTF-8 with the
+// first character of each word in its upper byte
+type StringOptions struct {
+	ByteOrder StringByteOrder
+	Padding   StringPadding
+	Layout    StringLayout
+	Encoding  StringEncoding
+}
+
+// ErrStringTooLong Error when a string does not fit in its words
+var ErrStringTooLong = errors.New("the string is too long for its words")
+
+// ErrInvalidStringLength Error when the length prefix of a string exceeds its words
+var ErrInvalidStringLength = errors.New("the length of the string exceeds its words")
+
+// SetStringOptions Sets the layout and encoding of strings read and written by ReadString and WriteString
+func (c *Client) SetStringOptions(options StringOptions) {
+	c.Lock()
+	defer c.Unlock()
+	c.stringOptions = options
+}
+
+// StringOptions Returns the layout and encoding of strings read and written by ReadString and WriteString
+func (c *Client) StringOptions() StringOptions {
+	c.Lock()
+	defer c.Unlock()
+	return c.stringOptions
+}
+
+// decode Decodes a string from its words
+func (o StringOptions) decode(words []uint16) (string, error) {
+	length := -1
+	if o.Layout == StringLayoutLengthPrefixed {
+		if len(words) < 1 {
+			return "", ErrInvalidStringLength
+		}
+		length = int(words[0])
+		words = words[1:]
+		if length > 2*len(words) {
+			return "", fmt.Errorf("%w: %d bytes in %d words", ErrInvalidStringLength, length, len(words))
+		}
+	}
+
+	data := make([]byte, 2*len(words))
+	for i, w := range words {
+		if o.ByteOrder == StringByteOrderLowFirst {
+			data[2*i], data[2*i+1] = byte(w), byte(w>>8)
+		} else {
+			data[2*i], data[2*i+1] = byte(w>>8), byte(w)
+		}
+	}
+	if length >= 0 {
+		data = data[:length]
+	} else {
+		if n := bytes.IndexByte(data, 0); n >= 0 {
+			data = data[:n]
+		}
+		if o.Padding == StringPaddingSpace {
+			data = bytes.TrimRight(data, " ")
+		}
+	}
+
+	if o.Encoding == StringEncodingShiftJIS {
+		decoded, e := japanese.ShiftJIS.NewDecoder().Bytes(data)
+		if e != nil {
+			return "", e
+		}
+		data = decoded
+	}
+	return string(data), nil
+}
+
+// encode Encodes a string into the given number of words
+func (o StringOptions) encode(s string, n int) ([]uint16, error) {
+	data := []byte(s)
+	if o.Encoding == StringEncodingShiftJIS {
+		encoded, e := japanese.ShiftJIS.NewEncoder().Bytes(data)
+		if e != nil {
+			return nil, e
+		}
+		data = encoded
+	}
+
+	words := make([]uint16, n)
+	chars := words
+	if o.Layout == StringLayoutLengthPrefixed {
+		if n < 1 {
+			return nil, ErrStringTooLong
+		}
+		words[0] = uint16(len(data))
+		chars = words[1:]
+	}
+	if len(data) > 2*len(chars) {
+		return nil, fmt.Errorf("%w: %d bytes in %d words", ErrStringTooLong, len(data), len(chars))
+	}
+
+	padding := byte(0)
+	if o.Padding == StringPaddingSpace {
+		padding = ' '
+	}
+	padded := bytes.Repeat([]byte{padding}, 2*len(chars))
+	copy(padded, data)
+	for i := range chars {
+		if o.ByteOrder == StringByteOrderLowFirst {
+			chars[i] = uint16(padded[2*i+1])<<8 | uint16(padded[2*i])
+		} else {
+			chars[i] = uint16(padded[2*i])<<8 | uint16(padded[2*i+1])
+		}
+	}
+	return words, nil
+}
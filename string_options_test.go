@@ -0,0 +1,146 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options StringOptions
+		s       string
+		words   []uint16
+	}{
+		{
+			name:  "default",
+			s:     "ABC",
+			words: []uint16{0x4142, 0x4300, 0x0000},
+		},
+		{
+			name:    "low byte first",
+			options: StringOptions{ByteOrder: StringByteOrderLowFirst},
+			s:       "ABC",
+			words:   []uint16{0x4241, 0x0043, 0x0000},
+		},
+		{
+			name:    "space padding",
+			options: StringOptions{Padding: StringPaddingSpace},
+			s:       "ABC",
+			words:   []uint16{0x4142, 0x4320, 0x2020},
+		},
+		{
+			name:    "length prefixed",
+			options: StringOptions{Layout: StringLayoutLengthPrefixed},
+			s:       "ABC",
+			words:   []uint16{0x0003, 0x4142, 0x4300},
+		},
+		{
+			name: "length prefixed, low byte first and space padding",
+			options: StringOptions{
+				ByteOrder: StringByteOrderLowFirst,
+				Padding:   StringPaddingSpace,
+				Layout:    StringLayoutLengthPrefixed,
+			},
+			s:     "A",
+			words: []uint16{0x0001, 0x2041, 0x2020},
+		},
+		{
+			name:  "full words",
+			s:     "ABCDEF",
+			words: []uint16{0x4142, 0x4344, 0x4546},
+		},
+		{
+			name:  "UTF-8",
+			s:     "é",
+			words: []uint16{0xc3a9, 0x0000, 0x0000},
+		},
+		{
+			// カナ is 0x834a 0x8369 in Shift-JIS
+			name:    "Shift-JIS",
+			options: StringOptions{Encoding: StringEncodingShiftJIS},
+			s:       "カナ",
+			words:   []uint16{0x834a, 0x8369, 0x0000},
+		},
+		{
+			name:    "Shift-JIS with half-width katakana",
+			options: StringOptions{Encoding: StringEncodingShiftJIS, Padding: StringPaddingSpace},
+			s:       "ｶA",
+			words:   []uint16{0xb641, 0x2020, 0x2020},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			words, e := test.options.encode(test.s, len(test.words))
+			require.NoError(t, e)
+			assert.Equal(t, test.words, words)
+			s, e := test.options.decode(test.words)
+			require.NoError(t, e)
+			assert.Equal(t, test.s, s)
+		})
+	}
+}
+
+func TestStringOptionsDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		options StringOptions
+		words   []uint16
+		s       string
+	}{
+		{"up to the first NUL", StringOptions{}, []uint16{0x4142, 0x0043}, "AB"},
+		{"NUL padding keeps spaces", StringOptions{}, []uint16{0x4120, 0x2000}, "A  "},
+		{"trailing spaces", StringOptions{Padding: StringPaddingSpace}, []uint16{0x2041, 0x2020}, " A"},
+		{"length before a NUL", StringOptions{Layout: StringLayoutLengthPrefixed}, []uint16{0x0003, 0x4100, 0x4200},
+			"A\x00B"},
+		{"empty", StringOptions{Layout: StringLayoutLengthPrefixed}, []uint16{0x0000, 0x4142}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, e := test.options.decode(test.words)
+			require.NoError(t, e)
+			assert.Equal(t, test.s, s)
+		})
+	}
+}
+
+func TestStringOptionsInvalid(t *testing.T) {
+	prefixed := StringOptions{Layout: StringLayoutLengthPrefixed}
+
+	_, e := StringOptions{}.encode("ABCDE", 2)
+	assert.True(t, errors.Is(e, ErrStringTooLong), "error %v", e)
+	_, e = prefixed.encode("ABC", 2)
+	assert.True(t, errors.Is(e, ErrStringTooLong), "error %v", e)
+	_, e = prefixed.encode("", 0)
+	assert.True(t, errors.Is(e, ErrStringTooLong), "error %v", e)
+	_, e = StringOptions{Encoding: StringEncodingShiftJIS}.encode("カナカ", 2)
+	assert.True(t, errors.Is(e, ErrStringTooLong), "error %v", e)
+	_, e = StringOptions{Encoding: StringEncodingShiftJIS}.encode("😀", 2)
+	assert.Error(t, e, "character without Shift-JIS encoding")
+
+	_, e = prefixed.decode([]uint16{0x0005, 0x4142, 0x4344})
+	assert.True(t, errors.Is(e, ErrInvalidStringLength), "error %v", e)
+	_, e = prefixed.decode(nil)
+	assert.True(t, errors.Is(e, ErrInvalidStringLength), "error %v", e)
+}
+
+func TestReadWriteString(t *testing.T) {
+	_, c := newSimulator(t)
+	c.SetStringOptions(StringOptions{Encoding: StringEncodingShiftJIS, ByteOrder: StringByteOrderLowFirst})
+	assert.Equal(t, StringOptions{Encoding: StringEncodingShiftJIS, ByteOrder: StringByteOrderLowFirst},
+		c.StringOptions())
+
+	require.NoError(t, c.WriteString(MemoryAreaDMWord, 10, 3, "カナ"))
+	words, e := c.ReadWords(MemoryAreaDMWord, 10, 3)
+	require.NoError(t, e)
+	assert.Equal(t, []uint16{0x4a83, 0x6983, 0x0000}, words)
+	s, e := c.ReadString(MemoryAreaDMWord, 10, 3)
+	require.NoError(t, e)
+	assert.Equal(t, "カナ", s)
+
+	e = c.WriteString(MemoryAreaDMWord, 10, 1, "カナ")
+	assert.True(t, errors.Is(e, ErrStringTooLong), "error %v", e)
+}
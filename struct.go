@@ -0,0 +1,508 @@
+package fins
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidStruct Error when a struct cannot be mapped onto PLC memory
+var ErrInvalidStruct = errors.New("invalid struct for PLC memory")
+
+// structField A field of a struct mapped onto a word block, with its offset from the start of the block
+type structField struct {
+	name     string
+	index    []int
+	offset   int
+	bit      int
+	dataType DataType
+	length   int
+	count    int
+	order    WordOrder
+	hasOrder bool
+}
+
+// words Returns the number of words of the block the field occupies
+func (f *structField) words() int {
+	switch f.dataType {
+	case DataTypeBool:
+		return (f.bit + maxElements(f.count) + 15) / 16
+	case DataTypeString:
+		return (f.length + 1) / 2
+	}
+	return f.dataType.Words() * maxElements(f.count)
+}
+
+func maxElements(count int) int {
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// structLayout The fields of a struct mapped onto a word block, and the bits of each word they cover
+type structLayout struct {
+	fields []structField
+	masks  []uint16
+}
+
+var structLayouts sync.Map
+
+// layoutOf Returns the layout of a struct type, built once from the fins tags of its fields
+func layoutOf(t reflect.Type) (*structLayout, error) {
+	if l, ok := structLayouts.Load(t); ok {
+		return l.(*structLayout), nil
+	}
+	l := new(structLayout)
+	if _, e := l.add(t, nil, 0); e != nil {
+		return nil, e
+	}
+	for i := range l.fields {
+		f := &l.fields[i]
+		for len(l.masks) < f.offset+f.words() {
+			l.masks = append(l.masks, 0)
+		}
+		if f.dataType != DataTypeBool {
+			for w := f.offset; w < f.offset+f.words(); w++ {
+				if e := l.cover(f, w, 0xffff); e != nil {
+					return nil, e
+				}
+			}
+			continue
+		}
+		for k := 0; k < maxElements(f.count); k++ {
+			n := f.bit + k
+			if e := l.cover(f, f.offset+n/16, 1<<uint(n%16)); e != nil {
+				return nil, e
+			}
+		}
+	}
+	structLayouts.Store(t, l)
+	return l, nil
+}
+
+// cover Marks bits of a word of the block as covered by a field, rejecting bits already covered by another field
+func (l *structLayout) cover(f *structField, w int, mask uint16) error {
+	if l.masks[w]&mask != 0 {
+		return fmt.Errorf("%w: field %s overlaps another field in word %d", ErrInvalidStruct, f.name, w)
+	}
+	l.masks[w] |= mask
+	return nil
+}
+
+// add Adds the fields of a struct placed at base, and returns the number of words it occupies
+func (l *structLayout) add(t reflect.Type, index []int, base int) (int, error) {
+	next, end := 0, 0
+	lastBool, lastBit := -1, -1
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("fins")
+		if tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+		options, e := parseStructTag(tag)
+		if e != nil {
+			return 0, fmt.Errorf("%w: field %s: %v", ErrInvalidStruct, sf.Name, e)
+		}
+
+		f := structField{
+			name:  sf.Name,
+			index: append(append([]int{}, index...), i),
+		}
+		explicitOffset := false
+		if s, ok := options["offset"]; ok {
+			f.offset, e = strconv.Atoi(s)
+			if e != nil || f.offset < 0 {
+				return 0, fmt.Errorf("%w: field %s: offset %q", ErrInvalidStruct, sf.Name, s)
+			}
+			explicitOffset = true
+		} else {
+			f.offset = next
+		}
+
+		element := sf.Type
+		if element.Kind() == reflect.Array {
+			f.count = element.Len()
+			element = element.Elem()
+		}
+
+		if _, ok := options["type"]; !ok && element.Kind() == reflect.Struct {
+			size, e := l.structSize(element)
+			if e != nil {
+				return 0, e
+			}
+			for k := 0; k < maxElements(f.count); k++ {
+				elementIndex := f.index
+				if f.count > 0 {
+					elementIndex = append(append([]int{}, f.index...), -1-k)
+				}
+				if _, e := l.add(element, elementIndex, base+f.offset+k*size); e != nil {
+					return 0, e
+				}
+			}
+			next = f.offset + size*maxElements(f.count)
+			end = maxInt(end, next)
+			lastBool = -1
+			continue
+		}
+
+		f.dataType, e = structFieldDataType(element, options["type"])
+		if e != nil {
+			return 0, fmt.Errorf("%w: field %s: %v", ErrInvalidStruct, sf.Name, e)
+		}
+		if s, ok := options["order"]; ok {
+			switch s {
+			case "low":
+				f.order = WordOrderLowFirst
+			case "high":
+				f.order = WordOrderHighFirst
+			default:
+				return 0, fmt.Errorf("%w: field %s: order %q", ErrInvalidStruct, sf.Name, s)
+			}
+			f.hasOrder = true
+		}
+		if f.dataType == DataTypeString {
+			if f.count > 0 {
+				return 0, fmt.Errorf("%w: field %s: array of strings", ErrInvalidStruct, sf.Name)
+			}
+			f.length, e = strconv.Atoi(options["length"])
+			if e != nil || f.length < 1 {
+				return 0, fmt.Errorf("%w: field %s: string without length", ErrInvalidStruct, sf.Name)
+			}
+		}
+		if f.dataType == DataTypeBool {
+			f.bit = 0
+			if !explicitOffset && lastBool >= 0 && lastBit < 15 {
+				f.offset, f.bit = lastBool, lastBit+1
+			}
+			if s, ok := options["bit"]; ok {
+				f.bit, e = strconv.Atoi(s)
+				if e != nil || f.bit < 0 || f.bit > 15 {
+					return 0, fmt.Errorf("%w: field %s: bit %q", ErrInvalidStruct, sf.Name, s)
+				}
+				if !explicitOffset && (lastBool < 0 || f.bit <= lastBit) {
+					f.offset = next
+				}
+			}
+			n := f.bit + maxElements(f.count) - 1
+			lastBool, lastBit = f.offset+n/16, n%16
+		} else {
+			lastBool = -1
+		}
+
+		next = f.offset + f.words()
+		end = maxInt(end, next)
+		f.offset += base
+		l.fields = append(l.fields, f)
+	}
+	return end, nil
+}
+
+// structSize Returns the number of words of a nested struct
+func (l *structLayout) structSize(t reflect.Type) (int, error) {
+	nested := new(structLayout)
+	return nested.add(t, nil, 0)
+}
+
+// parseStructTag Parses a fins tag of comma separated key=value options
+func parseStructTag(tag string) (map[string]string, error) {
+	options := make(map[string]string)
+	if tag == "" {
+		return options, nil
+	}
+	for _, option := range strings.Split(tag, ",") {
+		kv := strings.SplitN(option, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || key == "" {
+			return nil, fmt.Errorf("option %q", option)
+		}
+		switch key {
+		case "offset", "bit", "type", "order", "length":
+		default:
+			return nil, fmt.Errorf("unknown option %q", key)
+		}
+		options[key] = strings.TrimSpace(kv[1])
+	}
+	return options, nil
+}
+
+// structFieldDataType Returns the data type of a field, given by its type option or inferred from its Go type
+func structFieldDataType(t reflect.Type, name string) (DataType, error) {
+	if name == "" {
+		switch t.Kind() {
+		case reflect.Bool:
+			return DataTypeBool, nil
+		case reflect.Int16:
+			return DataTypeInt, nil
+		case reflect.Uint16:
+			return DataTypeUint, nil
+		case reflect.Int32:
+			return DataTypeDint, nil
+		case reflect.Uint32:
+			return DataTypeUdint, nil
+		case reflect.Int64:
+			return DataTypeLint, nil
+		case reflect.Uint64:
+			return DataTypeUlint, nil
+		case reflect.Float32:
+			return DataTypeReal, nil
+		case reflect.Float64:
+			return DataTypeLreal, nil
+		case reflect.String:
+			return DataTypeString, nil
+		}
+		return 0, fmt.Errorf("no data type for %v", t)
+	}
+
+	dataType, length, e := ParseDataType(name)
+	if e != nil {
+		return 0, e
+	}
+	if length != 0 {
+		return 0, fmt.Errorf("array data type %q, use a Go array", name)
+	}
+	ok := false
+	switch dataType {
+	case DataTypeBool:
+		ok = t.Kind() == reflect.Bool
+	case DataTypeString:
+		ok = t.Kind() == reflect.String
+	case DataTypeReal, DataTypeLreal:
+		ok = t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+	default:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			ok = t.Bits() >= dataTypeValues[dataType].Bits()
+		}
+	}
+	if !ok {
+		return 0, fmt.Errorf("%v does not hold %v", t, dataType)
+	}
+	return dataType, nil
+}
+
+// fieldValue Returns the value of a field, following the negative indexes of arrays of nested structs
+func fieldValue(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if i < 0 {
+			v = v.Index(-1 - i)
+		} else {
+			v = v.Field(i)
+		}
+	}
+	return v
+}
+
+// decode Decodes the fields of a struct from a word block
+func (l *structLayout) decode(block []uint16, v reflect.Value, order WordOrder, options StringOptions) error {
+	for i := range l.fields {
+		f := &l.fields[i]
+		fv := fieldValue(v, f.index)
+		fieldOrder := order
+		if f.hasOrder {
+			fieldOrder = f.order
+		}
+
+		switch f.dataType {
+		case DataTypeBool:
+			for k := 0; k < maxElements(f.count); k++ {
+				n := f.bit + k
+				value := block[f.offset+n/16]&(1<<uint(n%16)) != 0
+				structElement(fv, f.count, k).SetBool(value)
+			}
+		case DataTypeString:
+			value, e := options.decode(block[f.offset : f.offset+f.words()])
+			if e != nil {
+				return fmt.Errorf("%w: field %s", e, f.name)
+			}
+			fv.SetString(value)
+		default:
+			size := f.dataType.Words()
+			for k := 0; k < maxElements(f.count); k++ {
+				o := f.offset + k*size
+				value, e := decodeTagElement(f.dataType, block[o:o+size], fieldOrder)
+				if e != nil {
+					return fmt.Errorf("%w: field %s", e, f.name)
+				}
+				if e := setStructElement(structElement(fv, f.count, k), value); e != nil {
+					return fmt.Errorf("%w: field %s", e, f.name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// encode Encodes the fields of a struct into a word block
+func (l *structLayout) encode(block []uint16, v reflect.Value, order WordOrder, options StringOptions) error {
+	for i := range l.fields {
+		f := &l.fields[i]
+		fv := fieldValue(v, f.index)
+		fieldOrder := order
+		if f.hasOrder {
+			fieldOrder = f.order
+		}
+
+		switch f.dataType {
+		case DataTypeBool:
+			for k := 0; k < maxElements(f.count); k++ {
+				n := f.bit + k
+				mask := uint16(1) << uint(n%16)
+				if structElement(fv, f.count, k).Bool() {
+					block[f.offset+n/16] |= mask
+				} else {
+					block[f.offset+n/16] &^= mask
+				}
+			}
+		case DataTypeString:
+			words, e := options.encode(fv.String(), f.words())
+			if e != nil {
+				return fmt.Errorf("%w: field %s", e, f.name)
+			}
+			copy(block[f.offset:], words)
+		default:
+			size := f.dataType.Words()
+			for k := 0; k < maxElements(f.count); k++ {
+				words, e := encodeTagElement(f.dataType, structElement(fv, f.count, k), fieldOrder)
+				if e != nil {
+					return fmt.Errorf("%w: field %s", e, f.name)
+				}
+				copy(block[f.offset+k*size:], words)
+			}
+		}
+	}
+	return nil
+}
+
+// setStructElement Sets an element of a field to a decoded value, which must be within the range of the field as
+// encodeTagElement requires of the values it writes
+func setStructElement(v reflect.Value, value interface{}) error {
+	rv := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.Uint() > math.MaxInt64 {
+				return fmt.Errorf("%w: %v out of range of %v", ErrInvalidTagValue, value, v.Type())
+			}
+			i = int64(rv.Uint())
+		default:
+			return fmt.Errorf("%w: %T into %v", ErrInvalidTagValue, value, v.Type())
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("%w: %v out of range of %v", ErrInvalidTagValue, value, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.Int() < 0 {
+				return fmt.Errorf("%w: %v out of range of %v", ErrInvalidTagValue, value, v.Type())
+			}
+			u = uint64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u = rv.Uint()
+		default:
+			return fmt.Errorf("%w: %T into %v", ErrInvalidTagValue, value, v.Type())
+		}
+		if v.OverflowUint(u) {
+			return fmt.Errorf("%w: %v out of range of %v", ErrInvalidTagValue, value, v.Type())
+		}
+		v.SetUint(u)
+	default:
+		v.Set(rv.Convert(v.Type()))
+	}
+	return nil
+}
+
+func structElement(v reflect.Value, count int, k int) reflect.Value {
+	if count < 1 {
+		return v
+	}
+	return v.Index(k)
+}
+
+func structLayoutOf(v interface{}) (*structLayout, reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, reflect.Value{}, fmt.Errorf("%w: %T", ErrInvalidStruct, v)
+	}
+	l, e := layoutOf(rv.Type())
+	if e != nil {
+		return nil, reflect.Value{}, e
+	}
+	if len(l.masks) == 0 {
+		return nil, reflect.Value{}, fmt.Errorf("%w: %T has no fields", ErrInvalidStruct, v)
+	}
+	return l, rv, nil
+}
+
+// ReadStruct Reads a block of words in one request and decodes it into the struct v points to. The fins tag of each
+// field gives its options, separated by commas:
+//
+//	offset=n  offset in words from the start of the block, after the previous field by default
+//	type=t    data type, such as INT, UDINT_BCD or REAL, inferred from the Go type by default
+//	bit=n     bit number of a bool field, after the previous bool field of the same word by default
+//	order=o   word order of values longer than one word, low or high, that of the client by default
+//	length=n  number of bytes of a string field, stored in the string options of the client
+//
+// Go arrays hold consecutive elements, nested structs are placed at the offset of their field, and fields tagged
+// fins:"-" are skipped
+func (c *Client) ReadStruct(memoryArea byte, address uint16, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w: %T is not a pointer", ErrInvalidStruct, v)
+	}
+	l, rv, e := structLayoutOf(v)
+	if e != nil {
+		return e
+	}
+	block, e := c.ReadWords(memoryArea, address, uint16(len(l.masks)))
+	if e != nil {
+		return e
+	}
+	return l.decode(block, rv, c.WordOrder(), c.StringOptions())
+}
+
+// WriteStruct Encodes a struct, or the struct v points to, and writes it as a block of words in one request. When the
+// fields do not cover every bit of the block, the block is read first so the other bits keep their value. See
+// ReadStruct for the layout of the block
+func (c *Client) WriteStruct(memoryArea byte, address uint16, v interface{}) error {
+	l, rv, e := structLayoutOf(v)
+	if e != nil {
+		return e
+	}
+	block := make([]uint16, len(l.masks))
+	for _, mask := range l.masks {
+		if mask != 0xffff {
+			block, e = c.ReadWords(memoryArea, address, uint16(len(l.masks)))
+			if e != nil {
+				return e
+			}
+			break
+		}
+	}
+	if e := l.encode(block, rv, c.WordOrder(), c.StringOptions()); e != nil {
+		return e
+	}
+	return c.WriteWords(memoryArea, address, block)
+}
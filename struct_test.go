@@ -0,0 +1,188 @@
+package fins
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testValve struct {
+	Open     bool
+	Fault    bool
+	Position uint16
+	Manual   bool
+}
+
+type testLine struct {
+	Valves  [2]testValve
+	Speed   float32  `fins:"offset=6"`
+	Alarm   bool     `fins:"offset=8,bit=3"`
+	Counts  [3]int16 `fins:"offset=9"`
+	Name    string   `fins:"length=6"`
+	Total   uint32   `fins:"type=UDINT_BCD,order=high"`
+	Comment string   `fins:"-"`
+	ignored int
+}
+
+func TestStructLayout(t *testing.T) {
+	l, e := layoutOf(reflect.TypeOf(testValve{}))
+	require.NoError(t, e)
+	assert.Equal(t, []structField{
+		{name: "Open", index: []int{0}, offset: 0, bit: 0, dataType: DataTypeBool},
+		{name: "Fault", index: []int{1}, offset: 0, bit: 1, dataType: DataTypeBool},
+		{name: "Position", index: []int{2}, offset: 1, dataType: DataTypeUint},
+		{name: "Manual", index: []int{3}, offset: 2, bit: 0, dataType: DataTypeBool},
+	}, l.fields)
+	assert.Equal(t, []uint16{0x0003, 0xffff, 0x0001}, l.masks)
+
+	l, e = layoutOf(reflect.TypeOf(testLine{}))
+	require.NoError(t, e)
+	offsets := make(map[string][]int)
+	for _, f := range l.fields {
+		offsets[f.name] = append(offsets[f.name], f.offset)
+	}
+	assert.Equal(t, map[string][]int{
+		"Open":     {0, 3},
+		"Fault":    {0, 3},
+		"Position": {1, 4},
+		"Manual":   {2, 5},
+		"Speed":    {6},
+		"Alarm":    {8},
+		"Counts":   {9},
+		"Name":     {12},
+		"Total":    {15},
+	}, offsets)
+	assert.Equal(t, []int{0, -2, 1}, l.fields[5].index, "Fault of the second valve")
+	assert.Equal(t, []uint16{
+		0x0003, 0xffff, 0x0001, 0x0003, 0xffff, 0x0001, // valves
+		0xffff, 0xffff, // speed
+		0x0008,                 // alarm
+		0xffff, 0xffff, 0xffff, // counts
+		0xffff, 0xffff, 0xffff, // name
+		0xffff, 0xffff, // total
+	}, l.masks)
+}
+
+func TestStructLayoutInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"overlapping words", struct {
+			A uint16
+			B uint32 `fins:"offset=0"`
+		}{}},
+		{"bool in a word field", struct {
+			A uint16
+			B bool `fins:"offset=0,bit=2"`
+		}{}},
+		{"overlapping bits", struct {
+			A bool `fins:"bit=3"`
+			B bool `fins:"offset=0,bit=3"`
+		}{}},
+		{"overlapping nested struct", struct {
+			A testValve
+			B uint16 `fins:"offset=2"`
+		}{}},
+		{"bit out of range", struct {
+			A bool `fins:"bit=16"`
+		}{}},
+		{"string without length", struct {
+			A string
+		}{}},
+		{"type too wide for the field", struct {
+			A int16 `fins:"type=DINT"`
+		}{}},
+		{"unknown option", struct {
+			A uint16 `fins:"size=2"`
+		}{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, e := layoutOf(reflect.TypeOf(test.v))
+			assert.True(t, errors.Is(e, ErrInvalidStruct), "error %v", e)
+		})
+	}
+}
+
+func TestReadWriteStruct(t *testing.T) {
+	_, c := newSimulator(t)
+	want := testLine{
+		Valves: [2]testValve{
+			{Open: true, Position: 450},
+			{Fault: true, Position: 12, Manual: true},
+		},
+		Speed:  12.5,
+		Alarm:  true,
+		Counts: [3]int16{-1, 0, 32767},
+		Name:   "LINE1",
+		Total:  12345678,
+	}
+	require.NoError(t, c.WriteStruct(MemoryAreaDMWord, 100, &want))
+
+	words, e := c.ReadWords(MemoryAreaDMWord, 100, 17)
+	require.NoError(t, e)
+	assert.Equal(t, uint16(0x0001), words[0])
+	assert.Equal(t, uint16(450), words[1])
+	assert.Equal(t, uint16(0x0002), words[3])
+	assert.Equal(t, uint16(0x0001), words[5])
+	assert.Equal(t, uint16(0x0008), words[8])
+	assert.Equal(t, []uint16{0x1234, 0x5678}, words[15:17])
+
+	got := testLine{Comment: "kept"}
+	require.NoError(t, c.ReadStruct(MemoryAreaDMWord, 100, &got))
+	want.Comment = "kept"
+	assert.Equal(t, want, got)
+
+	assert.True(t, errors.Is(c.ReadStruct(MemoryAreaDMWord, 100, got), ErrInvalidStruct), "struct by value")
+}
+
+func TestWriteStructPreservesOtherBits(t *testing.T) {
+	_, c := newSimulator(t)
+	require.NoError(t, c.WriteWords(MemoryAreaDMWord, 200, []uint16{0xffff, 0xffff, 0xffff}))
+
+	v := struct {
+		A bool
+		B bool   `fins:"bit=4"`
+		C uint16 `fins:"offset=2"`
+	}{A: true, C: 7}
+	require.NoError(t, c.WriteStruct(MemoryAreaDMWord, 200, v))
+
+	words, e := c.ReadWords(MemoryAreaDMWord, 200, 3)
+	require.NoError(t, e)
+	assert.Equal(t, []uint16{0xffef, 0xffff, 0x0007}, words)
+}
+
+func TestStructValueRange(t *testing.T) {
+	_, c := newSimulator(t)
+
+	// A signed PLC type in an unsigned field holds the values both can represent, in both directions
+	var unsigned struct {
+		V uint16 `fins:"type=INT"`
+	}
+	require.NoError(t, c.WriteWords(MemoryAreaDMWord, 300, []uint16{0xfffb}))
+	e := c.ReadStruct(MemoryAreaDMWord, 300, &unsigned)
+	assert.True(t, errors.Is(e, ErrInvalidTagValue), "read -5: error %v", e)
+	unsigned.V = 40000
+	e = c.WriteStruct(MemoryAreaDMWord, 300, unsigned)
+	assert.True(t, errors.Is(e, ErrInvalidTagValue), "write 40000: error %v", e)
+
+	unsigned.V = 5
+	require.NoError(t, c.WriteStruct(MemoryAreaDMWord, 300, unsigned))
+	unsigned.V = 0
+	require.NoError(t, c.ReadStruct(MemoryAreaDMWord, 300, &unsigned))
+	assert.Equal(t, uint16(5), unsigned.V)
+
+	var signed struct {
+		V int16 `fins:"type=UINT"`
+	}
+	require.NoError(t, c.WriteWords(MemoryAreaDMWord, 300, []uint16{40000}))
+	e = c.ReadStruct(MemoryAreaDMWord, 300, &signed)
+	assert.True(t, errors.Is(e, ErrInvalidTagValue), "read 40000: error %v", e)
+	signed.V = -5
+	e = c.WriteStruct(MemoryAreaDMWord, 300, signed)
+	assert.True(t, errors.Is(e, ErrInvalidTagValue), "write -5: error %v", e)
+}
@@ -0,0 +1,633 @@
+package fins
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DataType Data type of a tag, named as in the CX-Programmer symbol table
+type DataType byte
+
+const (
+	// DataTypeBool One bit, read and written as bool
+	DataTypeBool DataType = iota + 1
+
+	// DataTypeInt Signed 16-bit integer, read and written as int16
+	DataTypeInt
+
+	// DataTypeUint Unsigned 16-bit integer, read and written as uint16
+	DataTypeUint
+
+	// DataTypeDint Signed 32-bit integer, read and written as int32
+	DataTypeDint
+
+	// DataTypeUdint Unsigned 32-bit integer, read and written as uint32
+	DataTypeUdint
+
+	// DataTypeLint Signed 64-bit integer, read and written as int64
+	DataTypeLint
+
+	// DataTypeUlint Unsigned 64-bit integer, read and written as uint64
+	DataTypeUlint
+
+	// DataTypeUintBCD Four BCD digits, read and written as uint16
+	DataTypeUintBCD
+
+	// DataTypeUdintBCD Eight BCD digits, read and written as uint32
+	DataTypeUdintBCD
+
+	// DataTypeUlintBCD Sixteen BCD digits, read and written as uint64
+	DataTypeUlintBCD
+
+	// DataTypeReal IEEE 754 single precision number, read and written as float32
+	DataTypeReal
+
+	// DataTypeLreal IEEE 754 double precision number, read and written as float64
+	DataTypeLreal
+
+	// DataTypeWord One word, read and written as uint16
+	DataTypeWord
+
+	// DataTypeDword Two words, read and written as uint32
+	DataTypeDword
+
+	// DataTypeLword Four words, read and written as uint64
+	DataTypeLword
+
+	// DataTypeChannel One word, read and written as uint16
+	DataTypeChannel
+
+	// DataTypeString Characters stored two per word in the string options of the client, read and written as string
+	DataTypeString
+)
+
+var dataTypeNames = map[DataType]string{
+	DataTypeBool:     "BOOL",
+	DataTypeInt:      "INT",
+	DataTypeUint:     "UINT",
+	DataTypeDint:     "DINT",
+	DataTypeUdint:    "UDINT",
+	DataTypeLint:     "LINT",
+	DataTypeUlint:    "ULINT",
+	DataTypeUintBCD:  "UINT_BCD",
+	DataTypeUdintBCD: "UDINT_BCD",
+	DataTypeUlintBCD: "ULINT_BCD",
+	DataTypeReal:     "REAL",
+	DataTypeLreal:    "LREAL",
+	DataTypeWord:     "WORD",
+	DataTypeDword:    "DWORD",
+	DataTypeLword:    "LWORD",
+	DataTypeChannel:  "CHANNEL",
+	DataTypeString:   "STRING",
+}
+
+// dataTypeValues Go type of the value of one element of each data type
+var dataTypeValues = map[DataType]reflect.Type{
+	DataTypeBool:     reflect.TypeOf(false),
+	DataTypeInt:      reflect.TypeOf(int16(0)),
+	DataTypeUint:     reflect.TypeOf(uint16(0)),
+	DataTypeDint:     reflect.TypeOf(int32(0)),
+	DataTypeUdint:    reflect.TypeOf(uint32(0)),
+	DataTypeLint:     reflect.TypeOf(int64(0)),
+	DataTypeUlint:    reflect.TypeOf(uint64(0)),
+	DataTypeUintBCD:  reflect.TypeOf(uint16(0)),
+	DataTypeUdintBCD: reflect.TypeOf(uint32(0)),
+	DataTypeUlintBCD: reflect.TypeOf(uint64(0)),
+	DataTypeReal:     reflect.TypeOf(float32(0)),
+	DataTypeLreal:    reflect.TypeOf(float64(0)),
+	DataTypeWord:     reflect.TypeOf(uint16(0)),
+	DataTypeDword:    reflect.TypeOf(uint32(0)),
+	DataTypeLword:    reflect.TypeOf(uint64(0)),
+	DataTypeChannel:  reflect.TypeOf(uint16(0)),
+	DataTypeString:   reflect.TypeOf(""),
+}
+
+func (t DataType) String() string {
+	if name, ok := dataTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("DataType(%d)", byte(t))
+}
+
+// Words Returns the number of words of one element of the data type, 0 for BOOL and STRING
+func (t DataType) Words() int {
+	switch t {
+	case DataTypeInt, DataTypeUint, DataTypeUintBCD, DataTypeWord, DataTypeChannel:
+		return 1
+	case DataTypeDint, DataTypeUdint, DataTypeUdintBCD, DataTypeReal, DataTypeDword:
+		return 2
+	case DataTypeLint, DataTypeUlint, DataTypeUlintBCD, DataTypeLreal, DataTypeLword:
+		return 4
+	}
+	return 0
+}
+
+// ParseDataType Parses a data type of the CX-Programmer symbol table, such as INT, UDINT BCD, UDINT_BCD, INT[10],
+// STRING[20] or ARRAY[0..9] OF REAL, and returns it with its array length, 0 for a single element. The array length of
+// a STRING is its number of bytes
+func ParseDataType(s string) (DataType, int, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	length := 0
+	if strings.HasPrefix(text, "ARRAY[") {
+		n := strings.Index(text, "]")
+		if n < 0 {
+			return 0, 0, fmt.Errorf("%w: %q", ErrInvalidDataType, s)
+		}
+		bounds := strings.Split(text[len("ARRAY["):n], "..")
+		of := strings.TrimSpace(text[n+1:])
+		if len(bounds) != 2 || !strings.HasPrefix(of, "OF ") {
+			return 0, 0, fmt.Errorf("%w: %q", ErrInvalidDataType, s)
+		}
+		first, e1 := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		last, e2 := strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if e1 != nil || e2 != nil || last < first {
+			return 0, 0, fmt.Errorf("%w: %q", ErrInvalidDataType, s)
+		}
+		text = strings.TrimSpace(of[len("OF "):])
+		length = last - first + 1
+	} else if n := strings.IndexByte(text, '['); n >= 0 && strings.HasSuffix(text, "]") {
+		l, e := strconv.Atoi(strings.TrimSpace(text[n+1 : len(text)-1]))
+		if e != nil || l < 1 {
+			return 0, 0, fmt.Errorf("%w: %q", ErrInvalidDataType, s)
+		}
+		text = strings.TrimSpace(text[:n])
+		length = l
+	}
+	text = strings.Join(strings.Fields(text), "_")
+
+	for t, name := range dataTypeNames {
+		if name == text {
+			return t, length, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: %q", ErrInvalidDataType, s)
+}
+
+// ErrInvalidDataType Error when a data type of the symbol table is unknown
+var ErrInvalidDataType = errors.New("invalid data type")
+
+// ErrInvalidTag Error when a tag of the symbol table is inconsistent
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ErrUnknownTag Error when a tag is not in the tag database
+var ErrUnknownTag = errors.New("unknown tag")
+
+// ErrInvalidTagValue Error when a value does not have the type of its tag or does not fit in it
+var ErrInvalidTagValue = errors.New("invalid tag value")
+
+// Tag A named address of the PLC with its data type
+type Tag struct {
+	Name        string
+	Address     IOAddress
+	DataType    DataType
+	ArrayLength int
+	Description string
+}
+
+// Words Returns the number of words the tag occupies, 0 for BOOL
+func (t *Tag) Words() int {
+	if t.DataType == DataTypeString {
+		return (t.ArrayLength + 1) / 2
+	}
+	return t.DataType.Words() * t.elements()
+}
+
+func (t *Tag) elements() int {
+	if t.ArrayLength < 1 {
+		return 1
+	}
+	return t.ArrayLength
+}
+
+func (t *Tag) check() error {
+	if t.Name == "" {
+		return fmt.Errorf("%w: no name", ErrInvalidTag)
+	}
+	if _, ok := dataTypeNames[t.DataType]; !ok {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidTag, t.Name, t.DataType)
+	}
+	if t.DataType == DataTypeString && t.ArrayLength < 1 {
+		return fmt.Errorf("%w: %s: STRING without length", ErrInvalidTag, t.Name)
+	}
+	if t.DataType == DataTypeBool && !checkIsBitMemoryArea(t.Address.MemoryArea) {
+		return fmt.Errorf("%w: %s: BOOL at word address %v", ErrInvalidTag, t.Name, t.Address)
+	}
+	if t.DataType != DataTypeBool && !checkIsWordMemoryArea(t.Address.MemoryArea) {
+		return fmt.Errorf("%w: %s: %v at bit address %v", ErrInvalidTag, t.Name, t.DataType, t.Address)
+	}
+	return nil
+}
+
+// TagDatabase Tags by name, usually loaded from a symbol table exported by CX-Programmer or CX-Designer
+type TagDatabase struct {
+	tags  map[string]*Tag
+	names []string
+}
+
+// NewTagDatabase Creates a tag database holding the given tags
+func NewTagDatabase(tags []Tag) (*TagDatabase, error) {
+	db := new(TagDatabase)
+	db.tags = make(map[string]*Tag, len(tags))
+	for i := range tags {
+		if e := db.add(tags[i]); e != nil {
+			return nil, e
+		}
+	}
+	sort.Strings(db.names)
+	return db, nil
+}
+
+func (db *TagDatabase) add(tag Tag) error {
+	if e := tag.check(); e != nil {
+		return e
+	}
+	if _, ok := db.tags[tag.Name]; ok {
+		return fmt.Errorf("%w: %s: duplicate name", ErrInvalidTag, tag.Name)
+	}
+	db.tags[tag.Name] = &tag
+	db.names = append(db.names, tag.Name)
+	return nil
+}
+
+// symbolTableColumns Columns of a symbol table export, by their header without spaces
+var symbolTableColumns = map[string]int{
+	"NAME":          symbolTableName,
+	"SYMBOL":        symbolTableName,
+	"SYMBOLNAME":    symbolTableName,
+	"DATATYPE":      symbolTableDataType,
+	"TYPE":          symbolTableDataType,
+	"ADDRESS/VALUE": symbolTableAddress,
+	"ADDRESS":       symbolTableAddress,
+	"AT":            symbolTableAddress,
+	"COMMENT":       symbolTableComment,
+	"DESCRIPTION":   symbolTableComment,
+	"ARRAYSIZE":     symbolTableArrayLength,
+	"ARRAYLENGTH":   symbolTableArrayLength,
+}
+
+const (
+	symbolTableName = iota
+	symbolTableDataType
+	symbolTableAddress
+	symbolTableComment
+	symbolTableArrayLength
+	symbolTableColumnCount
+)
+
+// ReadTagDatabase Reads a tag database from a symbol table exported by CX-Programmer or CX-Designer as comma or tab
+// separated values. The columns are found from the header row, and are name, data type, address and comment when
+// there is none. Symbols without an address, such as constants and network variables, are skipped
+func ReadTagDatabase(r io.Reader) (*TagDatabase, error) {
+	data, e := io.ReadAll(r)
+	if e != nil {
+		return nil, e
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	firstLine := data
+	if n := bytes.IndexByte(data, '\n'); n >= 0 {
+		firstLine = data[:n]
+	}
+	if bytes.IndexByte(firstLine, '\t') >= 0 {
+		reader.Comma = '\t'
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, e := reader.ReadAll()
+	if e != nil {
+		return nil, e
+	}
+
+	columns := []int{0, 1, 2, 3, -1}
+	first := 0
+	if len(records) > 0 {
+		header := []int{-1, -1, -1, -1, -1}
+		for i, field := range records[0] {
+			key := strings.ToUpper(strings.Join(strings.Fields(field), ""))
+			if column, ok := symbolTableColumns[key]; ok && header[column] < 0 {
+				header[column] = i
+			}
+		}
+		if header[symbolTableName] >= 0 && header[symbolTableDataType] >= 0 && header[symbolTableAddress] >= 0 {
+			columns = header
+			first = 1
+		}
+	}
+
+	db := new(TagDatabase)
+	db.tags = make(map[string]*Tag)
+	for i := first; i < len(records); i++ {
+		tag, ok, e := decodeSymbol(records[i], columns)
+		if e != nil {
+			return nil, fmt.Errorf("symbol table line %d: %w", i+1, e)
+		}
+		if !ok {
+			continue
+		}
+		if e := db.add(tag); e != nil {
+			return nil, fmt.Errorf("symbol table line %d: %w", i+1, e)
+		}
+	}
+	sort.Strings(db.names)
+	return db, nil
+}
+
+// LoadTagDatabase Reads a tag database from a symbol table file, see ReadTagDatabase
+func LoadTagDatabase(name string) (*TagDatabase, error) {
+	f, e := os.Open(name)
+	if e != nil {
+		return nil, e
+	}
+	defer f.Close()
+	return ReadTagDatabase(f)
+}
+
+func decodeSymbol(record []string, columns []int) (Tag, bool, error) {
+	fields := make([]string, symbolTableColumnCount)
+	for i, column := range columns {
+		if column >= 0 && column < len(record) {
+			fields[i] = strings.TrimSpace(record[column])
+		}
+	}
+	if fields[symbolTableName] == "" || fields[symbolTableAddress] == "" ||
+		strings.EqualFold(fields[symbolTableDataType], "NUMBER") {
+		return Tag{}, false, nil
+	}
+
+	dataType, length, e := ParseDataType(fields[symbolTableDataType])
+	if e != nil {
+		return Tag{}, false, e
+	}
+	if fields[symbolTableArrayLength] != "" {
+		length, e = strconv.Atoi(fields[symbolTableArrayLength])
+		if e != nil || length < 0 {
+			return Tag{}, false, fmt.Errorf("%w: array size %q", ErrInvalidTag, fields[symbolTableArrayLength])
+		}
+	}
+	address, e := ParseIOAddress(strings.TrimPrefix(fields[symbolTableAddress], "%"))
+	if e != nil {
+		return Tag{}, false, e
+	}
+	tag := Tag{
+		Name:        fields[symbolTableName],
+		Address:     address,
+		DataType:    dataType,
+		ArrayLength: length,
+		Description: fields[symbolTableComment],
+	}
+	return tag, true, nil
+}
+
+// Lookup Returns the tag with the given name
+func (db *TagDatabase) Lookup(name string) (Tag, bool) {
+	tag, ok := db.tags[name]
+	if !ok {
+		return Tag{}, false
+	}
+	return *tag, true
+}
+
+// Tags Returns all tags sorted by name
+func (db *TagDatabase) Tags() []Tag {
+	tags := make([]Tag, len(db.names))
+	for i, name := range db.names {
+		tags[i] = *db.tags[name]
+	}
+	return tags
+}
+
+// SetTagDatabase Sets the tag database used by ReadTag and WriteTag
+func (c *Client) SetTagDatabase(db *TagDatabase) {
+	c.Lock()
+	defer c.Unlock()
+	c.tags = db
+}
+
+// TagDatabase Returns the tag database used by ReadTag and WriteTag
+func (c *Client) TagDatabase() *TagDatabase {
+	c.Lock()
+	defer c.Unlock()
+	return c.tags
+}
+
+func (c *Client) lookupTag(name string) (Tag, error) {
+	db := c.TagDatabase()
+	if db == nil {
+		return Tag{}, fmt.Errorf("%w: %s", ErrUnknownTag, name)
+	}
+	tag, ok := db.Lookup(name)
+	if !ok {
+		return Tag{}, fmt.Errorf("%w: %s", ErrUnknownTag, name)
+	}
+	return tag, nil
+}
+
+// ReadTag Reads the value of a tag, of the Go type of its data type or a slice of it for arrays. Values of more than
+// one word are stored in the word order of the client, and strings in its string options
+func (c *Client) ReadTag(name string) (interface{}, error) {
+	tag, e := c.lookupTag(name)
+	if e != nil {
+		return nil, e
+	}
+	if tag.DataType == DataTypeBool {
+		bits, e := c.ReadBits(tag.Address.MemoryArea, tag.Address.Address, tag.Address.BitOffset,
+			uint16(tag.elements()))
+		if e != nil {
+			return nil, e
+		}
+		return tag.decode(bits, nil, c.WordOrder(), c.StringOptions())
+	}
+
+	words, e := c.ReadWords(tag.Address.MemoryArea, tag.Address.Address, uint16(tag.Words()))
+	if e != nil {
+		return nil, e
+	}
+	return tag.decode(nil, words, c.WordOrder(), c.StringOptions())
+}
+
+// decode Decodes the value of the tag from its bits, or from its words stored in the given word order and string
+// options
+func (t *Tag) decode(bits []bool, words []uint16, order WordOrder, options StringOptions) (interface{}, error) {
+	switch t.DataType {
+	case DataTypeBool:
+		if t.ArrayLength < 1 {
+			return bits[0], nil
+		}
+		return bits, nil
+	case DataTypeString:
+		return options.decode(words)
+	}
+
+	n := t.DataType.Words()
+	if t.ArrayLength < 1 {
+		return decodeTagElement(t.DataType, words, order)
+	}
+	values := reflect.MakeSlice(reflect.SliceOf(dataTypeValues[t.DataType]), t.ArrayLength, t.ArrayLength)
+	for i := 0; i < t.ArrayLength; i++ {
+		v, e := decodeTagElement(t.DataType, words[i*n:(i+1)*n], order)
+		if e != nil {
+			return nil, e
+		}
+		values.Index(i).Set(reflect.ValueOf(v))
+	}
+	return values.Interface(), nil
+}
+
+// WriteTag Writes the value of a tag, of any integer or floating point type that fits its data type or a slice of it
+// for arrays
+func (c *Client) WriteTag(name string, value interface{}) error {
+	tag, e := c.lookupTag(name)
+	if e != nil {
+		return e
+	}
+	v := reflect.ValueOf(value)
+
+	if tag.DataType == DataTypeString {
+		if v.Kind() != reflect.String {
+			return fmt.Errorf("%w: %s: %T", ErrInvalidTagValue, tag.Name, value)
+		}
+		words, e := c.StringOptions().encode(v.String(), tag.Words())
+		if e != nil {
+			return fmt.Errorf("%w: %s", e, tag.Name)
+		}
+		return c.WriteWords(tag.Address.MemoryArea, tag.Address.Address, words)
+	}
+
+	elements := []reflect.Value{v}
+	if tag.ArrayLength > 0 {
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array || v.Len() != tag.ArrayLength {
+			return fmt.Errorf("%w: %s: %T of length %d expected", ErrInvalidTagValue, tag.Name, value,
+				tag.ArrayLength)
+		}
+		elements = make([]reflect.Value, v.Len())
+		for i := range elements {
+			elements[i] = v.Index(i)
+		}
+	}
+
+	if tag.DataType == DataTypeBool {
+		bits := make([]bool, len(elements))
+		for i, element := range elements {
+			if element.Kind() != reflect.Bool {
+				return fmt.Errorf("%w: %s: %T", ErrInvalidTagValue, tag.Name, value)
+			}
+			bits[i] = element.Bool()
+		}
+		return c.WriteBits(tag.Address.MemoryArea, tag.Address.Address, tag.Address.BitOffset, bits)
+	}
+
+	order := c.WordOrder()
+	words := make([]uint16, 0, tag.Words())
+	for _, element := range elements {
+		w, e := encodeTagElement(tag.DataType, element, order)
+		if e != nil {
+			return fmt.Errorf("%w: %s: %v", e, tag.Name, element)
+		}
+		words = append(words, w...)
+	}
+	return c.WriteWords(tag.Address.MemoryArea, tag.Address.Address, words)
+}
+
+// decodeTagElement Decodes one element of a word data type stored in the given word order
+func decodeTagElement(t DataType, words []uint16, order WordOrder) (interface{}, error) {
+	raw := joinWords(words, order)
+	switch t {
+	case DataTypeInt:
+		return int16(raw), nil
+	case DataTypeDint:
+		return int32(raw), nil
+	case DataTypeLint:
+		return int64(raw), nil
+	case DataTypeUint, DataTypeWord, DataTypeChannel:
+		return uint16(raw), nil
+	case DataTypeUdint, DataTypeDword:
+		return uint32(raw), nil
+	case DataTypeUlint, DataTypeLword:
+		return raw, nil
+	case DataTypeReal:
+		return math.Float32frombits(uint32(raw)), nil
+	case DataTypeLreal:
+		return math.Float64frombits(raw), nil
+	}
+
+	n, e := decodeBCDValue(raw, len(words))
+	if e != nil {
+		return nil, e
+	}
+	return reflect.ValueOf(n).Convert(dataTypeValues[t]).Interface(), nil
+}
+
+// encodeTagElement Encodes one element of a word data type in the given word order
+func encodeTagElement(t DataType, v reflect.Value, order WordOrder) ([]uint16, error) {
+	n := t.Words()
+	bits := uint(16 * n)
+	var raw uint64
+
+	switch {
+	case t == DataTypeReal || t == DataTypeLreal:
+		var f float64
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f = v.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(v.Uint())
+		default:
+			return nil, ErrInvalidTagValue
+		}
+		if t == DataTypeReal {
+			raw = uint64(math.Float32bits(float32(f)))
+		} else {
+			raw = math.Float64bits(f)
+		}
+
+	default:
+		var i int64
+		var u uint64
+		negative := false
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i = v.Int()
+			u = uint64(i)
+			negative = i < 0
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			u = v.Uint()
+			i = int64(u)
+		default:
+			return nil, ErrInvalidTagValue
+		}
+
+		switch t {
+		case DataTypeInt, DataTypeDint, DataTypeLint:
+			if !negative && u > uint64(1)<<(bits-1)-1 || negative && i < -(int64(1)<<(bits-1)) {
+				return nil, ErrInvalidTagValue
+			}
+			raw = uint64(i)
+		case DataTypeUintBCD, DataTypeUdintBCD, DataTypeUlintBCD:
+			if negative {
+				return nil, ErrInvalidTagValue
+			}
+			var e error
+			raw, e = encodeBCDValue(u, n)
+			if e != nil {
+				return nil, e
+			}
+		default:
+			if negative || bits < 64 && u>>bits != 0 {
+				return nil, ErrInvalidTagValue
+			}
+			raw = u
+		}
+	}
+
+	return splitWords(raw, n, order), nil
+}
@@ -0,0 +1,201 @@
+package fins
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDataType(t *testing.T) {
+	tests := []struct {
+		s        string
+		dataType DataType
+		length   int
+	}{
+		{"BOOL", DataTypeBool, 0},
+		{" int ", DataTypeInt, 0},
+		{"UDINT_BCD", DataTypeUdintBCD, 0},
+		{"UDINT BCD", DataTypeUdintBCD, 0},
+		{"INT[10]", DataTypeInt, 10},
+		{"STRING[20]", DataTypeString, 20},
+		{"ARRAY[0..9] OF REAL", DataTypeReal, 10},
+		{"ARRAY[1..4] OF UINT BCD", DataTypeUintBCD, 4},
+		{"array[ 5 .. 5 ] of word", DataTypeWord, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			dataType, length, e := ParseDataType(test.s)
+			require.NoError(t, e)
+			assert.Equal(t, test.dataType, dataType)
+			assert.Equal(t, test.length, length)
+		})
+	}
+
+	for _, s := range []string{"", "NUMBER", "TIMER", "INT[0]", "INT[", "ARRAY[9..0] OF INT", "ARRAY[0..9] INT",
+		"ARRAY[0..9] OF NUMBER"} {
+		_, _, e := ParseDataType(s)
+		assert.True(t, errors.Is(e, ErrInvalidDataType), "%q: error %v", s, e)
+	}
+}
+
+func TestLoadTagDatabase(t *testing.T) {
+	em1, e := MemoryAreaEMWord(1)
+	require.NoError(t, e)
+
+	tests := []struct {
+		name string
+		file string
+		want []Tag
+	}{
+		{
+			// Global symbol table copied from CX-Programmer, tab separated with rack location and usage columns
+			name: "CX-Programmer",
+			file: "testdata/symbols.txt",
+			want: []Tag{
+				{Name: "BatchCount", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 100},
+					DataType: DataTypeUintBCD, Description: "Batches since reset"},
+				{Name: "Conveyor", Address: IOAddress{MemoryArea: MemoryAreaCIOBit, Address: 100},
+					DataType: DataTypeBool, Description: "Conveyor motor"},
+				{Name: "Flow", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 310},
+					DataType: DataTypeReal, Description: "Flow, m3/h"},
+				{Name: "Recipe", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 300},
+					DataType: DataTypeString, ArrayLength: 16, Description: "Recipe name"},
+				{Name: "Running", Address: IOAddress{MemoryArea: MemoryAreaWRBit, Address: 0, BitOffset: 2},
+					DataType: DataTypeBool},
+				{Name: "Setpoints", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 200},
+					DataType: DataTypeInt, ArrayLength: 10, Description: "Recipe setpoints"},
+				{Name: "StartButton", Address: IOAddress{MemoryArea: MemoryAreaCIOBit, Address: 0},
+					DataType: DataTypeBool, Description: "Start push button"},
+				{Name: "StopButton", Address: IOAddress{MemoryArea: MemoryAreaCIOBit, Address: 0, BitOffset: 1},
+					DataType: DataTypeBool},
+				{Name: "Total", Address: IOAddress{MemoryArea: em1, Address: 200},
+					DataType: DataTypeUdint},
+			},
+		},
+		{
+			// Comma separated export with an AT column and quoted comments
+			name: "comma separated",
+			file: "testdata/symbols.csv",
+			want: []Tag{
+				{Name: "Alarms", Address: IOAddress{MemoryArea: MemoryAreaHRWord, Address: 10},
+					DataType: DataTypeWord, ArrayLength: 4, Description: "Alarm words, one per tank"},
+				{Name: "Level", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 1000},
+					DataType: DataTypeInt, Description: "Tank level"},
+				{Name: "Operator", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 1010},
+					DataType: DataTypeString, ArrayLength: 8},
+				{Name: "StartButton", Address: IOAddress{MemoryArea: MemoryAreaCIOBit, Address: 0},
+					DataType: DataTypeBool, Description: "Start push button"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, e := LoadTagDatabase(test.file)
+			require.NoError(t, e)
+			assert.Equal(t, test.want, db.Tags())
+
+			// Constants and symbols without an address are not tags
+			for _, name := range []string{"RecipeCount", "HmiCommand", "MaxLevel", "Mode"} {
+				_, ok := db.Lookup(name)
+				assert.False(t, ok, name)
+			}
+		})
+	}
+}
+
+func TestReadTagDatabaseColumns(t *testing.T) {
+	// Without a header row the columns are name, data type, address and comment
+	db, e := ReadTagDatabase(strings.NewReader("Level,INT,D1000,Tank level\nFull,BOOL,W3.15\n"))
+	require.NoError(t, e)
+	assert.Equal(t, []Tag{
+		{Name: "Full", Address: IOAddress{MemoryArea: MemoryAreaWRBit, Address: 3, BitOffset: 15},
+			DataType: DataTypeBool},
+		{Name: "Level", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 1000},
+			DataType: DataTypeInt, Description: "Tank level"},
+	}, db.Tags())
+
+	// Columns in another order, with the array size in its own column
+	db, e = ReadTagDatabase(strings.NewReader("Comment\tAddress\tSymbol Name\tType\tArray Size\n" +
+		"Recipe values\tD500\tValues\tDINT\t6\n"))
+	require.NoError(t, e)
+	tag, ok := db.Lookup("Values")
+	require.True(t, ok)
+	assert.Equal(t, Tag{Name: "Values", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 500},
+		DataType: DataTypeDint, ArrayLength: 6, Description: "Recipe values"}, tag)
+}
+
+func TestReadTagDatabaseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"unknown data type", "Name,Data Type,Address\nA,TIMER,T0\n", ErrInvalidDataType},
+		{"invalid address", "Name,Data Type,Address\nA,INT,X10\n", ErrInvalidAddress},
+		{"BOOL at a word address", "Name,Data Type,Address\nA,BOOL,D10\n", ErrInvalidTag},
+		{"INT at a bit address", "Name,Data Type,Address\nA,INT,D10.01\n", ErrInvalidTag},
+		{"duplicate name", "Name,Data Type,Address\nA,INT,D10\nA,INT,D11\n", ErrInvalidTag},
+		{"invalid array size", "Name,Data Type,Address,Array Size\nA,INT,D10,many\n", ErrInvalidTag},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, e := ReadTagDatabase(strings.NewReader(test.data))
+			assert.True(t, errors.Is(e, test.err), "error %v", e)
+		})
+	}
+}
+
+func TestTagStringOptions(t *testing.T) {
+	_, c := newSimulator(t)
+	db, e := NewTagDatabase([]Tag{
+		{Name: "Name", Address: IOAddress{MemoryArea: MemoryAreaDMWord, Address: 100}, DataType: DataTypeString,
+			ArrayLength: 8},
+	})
+	require.NoError(t, e)
+	c.SetTagDatabase(db)
+	c.SetStringOptions(StringOptions{
+		ByteOrder: StringByteOrderLowFirst,
+		Padding:   StringPaddingSpace,
+		Layout:    StringLayoutLengthPrefixed,
+	})
+
+	// Tags and structs store strings as ReadString and WriteString do
+	require.NoError(t, c.WriteTag("Name", "ABC"))
+	words, e := c.ReadWords(MemoryAreaDMWord, 100, 4)
+	require.NoError(t, e)
+	assert.Equal(t, []uint16{0x0003, 0x4241, 0x2043, 0x2020}, words)
+	s, e := c.ReadString(MemoryAreaDMWord, 100, 4)
+	require.NoError(t, e)
+	assert.Equal(t, "ABC", s)
+
+	require.NoError(t, c.WriteString(MemoryAreaDMWord, 100, 4, "XYZW"))
+	value, e := c.ReadTag("Name")
+	require.NoError(t, e)
+	assert.Equal(t, "XYZW", value)
+	values, e := c.ReadTags("Name")
+	require.NoError(t, e)
+	assert.Equal(t, "XYZW", values["Name"])
+
+	var v struct {
+		Name string `fins:"length=8"`
+	}
+	require.NoError(t, c.ReadStruct(MemoryAreaDMWord, 100, &v))
+	assert.Equal(t, "XYZW", v.Name)
+	v.Name = "QR"
+	require.NoError(t, c.WriteStruct(MemoryAreaDMWord, 100, v))
+	s, e = c.ReadString(MemoryAreaDMWord, 100, 4)
+	require.NoError(t, e)
+	assert.Equal(t, "QR", s)
+
+	// The length word leaves six bytes for the characters
+	e = c.WriteTag("Name", "1234567")
+	assert.True(t, errors.Is(e, ErrStringTooLong), "error %v", e)
+	v.Name = "1234567"
+	e = c.WriteStruct(MemoryAreaDMWord, 100, v)
+	assert.True(t, errors.Is(e, ErrStringTooLong), "error %v", e)
+	e = c.WriteTag("Name", 5)
+	assert.True(t, errors.Is(e, ErrInvalidTagValue), "error %v", e)
+}
@@ -1,16 +1,18 @@
 package fins
 
 import (
-	"bufio"
 	"log"
 	"net"
+	"sync"
 )
 
 // UDPServerProvider implements ServerProvider interface.
 type UDPServerProvider struct {
-	conn *net.UDPConn
-	resp []chan Frame
-	quit chan bool
+	conn    *net.UDPConn
+	handler func(header *Header, payload *Payload) (*Header, *Payload)
+	quit    chan bool
+
+	sync.Mutex
 }
 
 var _ ServerProvider = (*UDPServerProvider)(nil)
@@ -28,42 +30,57 @@ func NewUDPServerProvider(plcAddr string) (*UDPServerProvider, error) {
 
 	s := new(UDPServerProvider)
 	s.conn = conn
-	s.resp = make([]chan Frame, 256) //storage for all responses, sid is byte - only 256 values
 	s.quit = make(chan bool)
 	go s.listenLoop()
 	return s, nil
 }
 
+func (s *UDPServerProvider) register(handler func(header *Header, payload *Payload) (*Header, *Payload)) error {
+	s.Lock()
+	defer s.Unlock()
+	s.handler = handler
+	return nil
+}
+
 // CloseConnection Closes an Omron FINS connection
 func (s *UDPServerProvider) close() error {
-	s.quit <- true
-	s.conn.Close()
-	return nil
+	close(s.quit)
+	return s.conn.Close()
 }
 
 func (s *UDPServerProvider) listenLoop() {
+	buf := make([]byte, 2048)
 	for {
-		select {
-		case <-s.quit:
-			return
-		default:
-			buf := make([]byte, 2048)
-			n, err := bufio.NewReader(s.conn).Read(buf)
-			if err != nil {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
 				log.Fatal(err)
 			}
+		}
 
-			if n > 0 {
-				ans := decodeFrame(buf[0:n])
-				if err != nil {
-					log.Println("failed to parse response: ", err, " \nresponse: ", buf[0:n])
-				} else {
-					//c.resp[ans.Header.sid] <- *ans
-					log.Println("Received: ", ans.Header.sid, " - ", ans.Payload.CommandCode, " - ", ans.Payload.Data)
-				}
-			} else {
-				log.Println("cannot read response: ", buf)
-			}
+		if n < 12 {
+			log.Println("cannot parse command: ", buf[0:n])
+			continue
+		}
+
+		s.Lock()
+		handler := s.handler
+		s.Unlock()
+		if handler == nil {
+			continue
+		}
+
+		command := decodeFrame(append([]byte(nil), buf[0:n]...))
+		header, payload := handler(command.Header, command.Payload)
+		if header == nil {
+			continue
+		}
+		_, err = s.conn.WriteToUDP(encodeFrame(NewFrame(header, payload)), addr)
+		if err != nil {
+			log.Println("failed to send response: ", err)
 		}
 	}
 }
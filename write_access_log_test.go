@@ -0,0 +1,86 @@
+package fins
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteAccessLogRecord(t *testing.T) {
+	r := &WriteAccessLogRecord{
+		Source:      Address{Network: 1, Node: 20, Unit: 0xfe},
+		CommandCode: CommandCodeMemoryAreaWrite,
+		Time:        time.Date(2019, time.December, 31, 23, 59, 58, 0, time.UTC),
+	}
+	data := encodeWriteAccessLogRecord(r)
+	assert.Equal(t, []byte{0x01, 0x14, 0xfe, 0x00, 0x01, 0x02, 0x59, 0x58, 0x31, 0x23, 0x19, 0x12}, data)
+
+	log, e := decodeLogRecords(append([]byte{0x00, 0x40, 0x00, 0x01, 0x00, 0x01}, data...),
+		writeAccessLogRecordLength)
+	require.NoError(t, e)
+	writeLog, e := decodeWriteAccessLog(log, time.UTC)
+	require.NoError(t, e)
+	assert.Equal(t, &WriteAccessLog{Capacity: 64, Stored: 1, Records: []WriteAccessLogRecord{*r}}, writeLog)
+}
+
+func TestWriteAccessLog(t *testing.T) {
+	s, c := newSimulator(t)
+	other := newSimulatorClient(t, s, 3)
+
+	// More records than fit in one read, with only the writes of the second node at the end
+	for i := 0; i < 45; i++ {
+		writer := c
+		if i >= 40 {
+			writer = other
+		}
+		require.NoError(t, writer.WriteWords(MemoryAreaDMWord, uint16(i), []uint16{uint16(i)}))
+	}
+	// Reads are not logged
+	_, e := c.ReadWords(MemoryAreaDMWord, 0, 1)
+	require.NoError(t, e)
+
+	writeLog, e := c.ReadAllWriteAccessLog()
+	require.NoError(t, e)
+	assert.Equal(t, serverWriteAccessLogCapacity, writeLog.Capacity)
+	assert.Equal(t, 45, writeLog.Stored)
+	require.Len(t, writeLog.Records, 45)
+	for i, r := range writeLog.Records {
+		node := byte(2)
+		if i >= 40 {
+			node = 3
+		}
+		assert.Equal(t, Address{Network: 0, Node: node, Unit: 0}, r.Source, "record %d", i)
+		assert.Equal(t, CommandCodeMemoryAreaWrite, r.CommandCode)
+	}
+
+	writeLog, e = c.ReadWriteAccessLog(39, 3)
+	require.NoError(t, e)
+	require.Len(t, writeLog.Records, 3)
+	assert.Equal(t, byte(2), writeLog.Records[0].Source.Node)
+	assert.Equal(t, byte(3), writeLog.Records[1].Source.Node)
+
+	// The CPU unit reads at most logReadMax records per command
+	_, e = c.ReadWriteAccessLog(0, logReadMax+1)
+	var ec *EndCodeError
+	require.True(t, errors.As(e, &ec), "error %v", e)
+	assert.Equal(t, EndCodeParameterError, ec.EndCode)
+
+	// Once full, the oldest records are dropped
+	for i := 0; i < serverWriteAccessLogCapacity; i++ {
+		require.NoError(t, other.WriteWords(MemoryAreaDMWord, 0, []uint16{0}))
+	}
+	writeLog, e = c.ReadAllWriteAccessLog()
+	require.NoError(t, e)
+	assert.Equal(t, serverWriteAccessLogCapacity, writeLog.Stored)
+	require.Len(t, writeLog.Records, serverWriteAccessLogCapacity)
+	assert.Equal(t, byte(3), writeLog.Records[0].Source.Node)
+
+	require.NoError(t, c.ClearWriteAccessLog())
+	writeLog, e = c.ReadAllWriteAccessLog()
+	require.NoError(t, e)
+	assert.Equal(t, 0, writeLog.Stored)
+	assert.Empty(t, writeLog.Records)
+}